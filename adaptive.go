@@ -0,0 +1,220 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// LimitAlgorithm computes the concurrency limit of an adaptive executer.
+// Update is called, one at a time, when each function completes with
+// the current limit, the number of functions that were executing, the
+// latency of the function and whether the worker failed, and returns
+// the new limit.
+type LimitAlgorithm interface {
+	Update(limit int, inflight int, latency time.Duration, failed bool) int
+}
+
+type aimd struct {
+	lower     int
+	upper     int
+	threshold time.Duration
+	backoff   float64
+}
+
+// AIMD returns an additive-increase/multiplicative-decrease algorithm.
+// The limit is increased by one when a function completes within the
+// latency threshold while the limit is being used, and is multiplied
+// by the backoff ratio when a function exceeds the threshold or its
+// worker fails. The limit is kept between lower and upper.
+func AIMD(lower, upper int, threshold time.Duration, backoff float64) LimitAlgorithm {
+	if lower < 1 {
+		lower = 1
+	}
+	if upper < lower {
+		upper = lower
+	}
+	if backoff <= 0 || backoff >= 1 {
+		backoff = 0.9
+	}
+	return &aimd{lower: lower, upper: upper, threshold: threshold, backoff: backoff}
+}
+
+func (a *aimd) Update(limit int, inflight int, latency time.Duration, failed bool) int {
+	if failed || latency > a.threshold {
+		limit = int(float64(limit) * a.backoff)
+	} else if inflight*2 >= limit {
+		limit++
+	}
+	return clampLimit(limit, a.lower, a.upper)
+}
+
+type gradient struct {
+	lower     int
+	upper     int
+	minimum   time.Duration
+	smoothing float64
+	estimate  float64
+}
+
+// Gradient returns an algorithm, similar to TCP Vegas, that compares
+// the latency of each function with the minimum latency observed, and
+// reduces the limit in proportion when the latency increases, while
+// allowing a small queue (the square root of the limit) to grow the
+// limit. The limit is halved when a worker fails, and is kept between
+// lower and upper. The algorithm keeps state and must not be shared.
+func Gradient(lower, upper int) LimitAlgorithm {
+	if lower < 1 {
+		lower = 1
+	}
+	if upper < lower {
+		upper = lower
+	}
+	return &gradient{lower: lower, upper: upper, smoothing: 0.2}
+}
+
+func (g *gradient) Update(limit int, inflight int, latency time.Duration, failed bool) int {
+	if g.estimate == 0 || int(math.Round(g.estimate)) != limit {
+		g.estimate = float64(limit)
+	}
+
+	if failed {
+		g.estimate /= 2
+		return clampLimit(int(math.Round(g.estimate)), g.lower, g.upper)
+	}
+
+	if latency <= 0 {
+		latency = 1
+	}
+	if g.minimum == 0 || latency < g.minimum {
+		g.minimum = latency
+	}
+
+	ratio := math.Max(0.5, math.Min(1, float64(g.minimum)/float64(latency)))
+	target := g.estimate*ratio + math.Sqrt(g.estimate)
+	g.estimate = g.estimate*(1-g.smoothing) + target*g.smoothing
+	g.estimate = math.Max(float64(g.lower), math.Min(float64(g.upper), g.estimate))
+
+	return clampLimit(int(math.Round(g.estimate)), g.lower, g.upper)
+}
+
+func clampLimit(limit, lower, upper int) int {
+	if limit < lower {
+		return lower
+	}
+	if limit > upper {
+		return upper
+	}
+	return limit
+}
+
+type adaptiveKey struct {
+	a *Adaptive
+}
+
+type adaptiveSample struct {
+	failed bool
+}
+
+// Adaptive is an executer that adjusts the number of functions that it
+// executes simultaneously, based on their latency and the errors that
+// are seen by managers wrapped with the Manager method.
+type Adaptive struct {
+	mutex sync.Mutex
+	clock clock
+	alg   LimitAlgorithm
+	limit int
+	sem   *semaphore
+}
+
+// NewAdaptive returns an adaptive executer with the initial limit,
+// which is then adjusted by the algorithm, alg. If initial <= 0 then
+// the value provided by DefaultLimit will be used, and if alg is not
+// provided then the Gradient algorithm is used. If the context is done
+// while waiting, then the function is rejected with the context error.
+func NewAdaptive(initial int, alg LimitAlgorithm) *Adaptive {
+	return newAdaptive(initial, alg, realClock{})
+}
+
+func newAdaptive(initial int, alg LimitAlgorithm, c clock) *Adaptive {
+	if initial <= 0 {
+		initial = DefaultLimit
+	}
+	if initial <= 0 {
+		initial = 1
+	}
+	if alg == nil {
+		alg = Gradient(1, math.MaxInt32)
+	}
+	return &Adaptive{
+		clock: c,
+		alg:   alg,
+		limit: initial,
+		sem:   newSemaphore(int64(initial)),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (a *Adaptive) Limit() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.limit
+}
+
+func (a *Adaptive) Execute(ctx context.Context, f func(context.Context)) {
+	if err := a.sem.acquire(ctx, 1); err != nil {
+		Reject(ctx, f, err)
+		return
+	}
+
+	go func() {
+		defer a.sem.release(1)
+
+		s := &adaptiveSample{}
+		start := a.clock.Now()
+		f(context.WithValue(ctx, adaptiveKey{a}, s))
+		a.update(a.clock.Now().Sub(start), s.failed)
+	}()
+}
+
+func (a *Adaptive) update(latency time.Duration, failed bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	limit := a.alg.Update(a.limit, int(a.sem.current()), latency, failed)
+	if limit < 1 {
+		limit = 1
+	}
+	if limit != a.limit {
+		a.limit = limit
+		a.sem.resize(int64(limit))
+	}
+}
+
+type adaptiveManager struct {
+	a *Adaptive
+	m Manager
+}
+
+// Manager wraps a Manager, m, so that workers that complete with an
+// error, other than a context error, are reported to the algorithm
+// of this executer as failures. Wrap it with Recover, to also report
+// workers that panic.
+func (a *Adaptive) Manager(m Manager) Manager {
+	return &adaptiveManager{a: a, m: m}
+}
+
+func (w *adaptiveManager) Error() error {
+	return w.m.Error()
+}
+
+func (w *adaptiveManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	if *err != nil && !errors.Is(*err, context.Canceled) && !errors.Is(*err, context.DeadlineExceeded) {
+		if s, ok := ctx.Value(adaptiveKey{w.a}).(*adaptiveSample); ok {
+			s.failed = true
+		}
+	}
+	return w.m.Manage(ctx, c, idx, err)
+}
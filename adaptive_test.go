@@ -0,0 +1,157 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// simulateLatency returns the latency of a service that can process
+// capacity requests in parallel, after which requests are queued.
+func simulateLatency(capacity int, base time.Duration, inflight int) time.Duration {
+	if inflight <= capacity {
+		return base
+	}
+	return base * time.Duration(inflight) / time.Duration(capacity)
+}
+
+func simulateLimit(alg LimitAlgorithm, limit int, steps int, capacity int) (int, int) {
+	lower, upper := -1, -1
+	for i := 0; i < steps; i++ {
+		latency := simulateLatency(capacity, 10*time.Millisecond, limit)
+		limit = alg.Update(limit, limit, latency, false)
+		if i >= steps/2 {
+			if lower < 0 || limit < lower {
+				lower = limit
+			}
+			if upper < 0 || limit > upper {
+				upper = limit
+			}
+		}
+	}
+	return lower, upper
+}
+
+func TestAIMDSimulation(t *testing.T) {
+
+	alg := AIMD(1, 100, 15*time.Millisecond, 0.9)
+
+	for _, initial := range []int{1, 10, 100} {
+		lower, upper := simulateLimit(alg, initial, 1000, 10)
+		if lower < 10 || upper > 20 {
+			t.Errorf("Expecting limit from %d to converge between 10 and 20, got %d to %d", initial, lower, upper)
+		}
+	}
+
+	if limit := alg.Update(20, 20, time.Millisecond, true); limit != 18 {
+		t.Errorf("Expecting limit to decrease after failure, got %d", limit)
+	}
+
+	if limit := alg.Update(20, 5, time.Millisecond, false); limit != 20 {
+		t.Errorf("Expecting limit to not increase when not used, got %d", limit)
+	}
+}
+
+func TestGradientSimulation(t *testing.T) {
+
+	for _, initial := range []int{1, 10} {
+		lower, upper := simulateLimit(Gradient(1, 100), initial, 1000, 10)
+		if lower < 10 || upper > 20 {
+			t.Errorf("Expecting limit from %d to converge between 10 and 20, got %d to %d", initial, lower, upper)
+		}
+	}
+
+	if limit := Gradient(1, 100).Update(20, 20, time.Millisecond, true); limit != 10 {
+		t.Errorf("Expecting limit to halve after failure, got %d", limit)
+	}
+}
+
+type sample struct {
+	limit    int
+	inflight int
+	latency  time.Duration
+	failed   bool
+}
+
+// scriptAlgorithm records samples and returns the next limit from a script.
+type scriptAlgorithm struct {
+	mutex   sync.Mutex
+	limits  []int
+	samples []sample
+}
+
+func (s *scriptAlgorithm) Update(limit int, inflight int, latency time.Duration, failed bool) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.samples = append(s.samples, sample{limit, inflight, latency, failed})
+	if len(s.limits) > 0 {
+		limit = s.limits[0]
+		s.limits = s.limits[1:]
+	}
+	return limit
+}
+
+func TestAdaptiveExecuter(t *testing.T) {
+
+	c := newFakeClock()
+	alg := &scriptAlgorithm{limits: []int{1, 1, 1, 1, 3}}
+	a := newAdaptive(1, alg, c)
+
+	err := WorkFor(context.Background(), a, a.Manager(CancelNeverFirstError()), 5,
+		func(ctx context.Context, index int) error {
+			c.Advance(time.Duration(index) * time.Millisecond)
+			if index%2 == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err == nil {
+		t.Fatalf("Work group error is nil")
+	}
+
+	// The last sample is taken after the work group completes
+	var samples []sample
+	for len(samples) < 5 {
+		time.Sleep(time.Millisecond)
+		alg.mutex.Lock()
+		samples = alg.samples
+		alg.mutex.Unlock()
+	}
+
+	for i, s := range samples {
+		if s.limit != 1 || s.inflight != 1 {
+			t.Errorf("Expecting sample %d with limit and inflight of 1, got %d and %d", i, s.limit, s.inflight)
+		}
+		if s.latency != time.Duration(i)*time.Millisecond {
+			t.Errorf("Expecting sample %d with latency of %dms, got %s", i, i, s.latency)
+		}
+		if s.failed != (i%2 == 1) {
+			t.Errorf("Expecting sample %d to be failed: %t", i, i%2 == 1)
+		}
+	}
+
+	if a.Limit() != 3 {
+		t.Fatalf("Expecting limit of 3, got %d", a.Limit())
+	}
+
+	tokens := make(chan struct{}, 3)
+
+	WorkFor(context.Background(), a, CancelNeverFirstError(), 100,
+		func(ctx context.Context, index int) error {
+			select {
+			case tokens <- struct{}{}:
+				break
+			default:
+				t.Errorf("Worker %d must wait to send token", index)
+			}
+
+			time.Sleep(time.Millisecond)
+
+			<-tokens
+			return nil
+		},
+	)
+}
@@ -0,0 +1,111 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BudgetError is the error returned by the ErrorBudget manager when
+// the error budget is exceeded, and holds the errors of the workers.
+type BudgetError struct {
+	MultiError
+	Failed int
+	Total  int
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("error budget exceeded with %d of %d failed:", e.Failed, e.Total) + e.list()
+}
+
+// ErrorBudget is a manager that tolerates workers that complete with an
+// error, until the number of failures exceeds the budget.
+type ErrorBudget struct {
+	mutex     sync.Mutex
+	max       int
+	percent   float64
+	size      int
+	ncomplete int
+	exceeded  bool
+	errs      []*IndexedError
+}
+
+// CancelOnErrorBudget initializes a new manager that cancels the work
+// group context when the number of workers that complete with an error
+// exceeds max, or exceeds the given percentage of the workers in the work
+// group. If max < 0 or percent < 0, then that limit is not applied. The
+// percentage is only applied before all workers complete if the size of
+// the work group is known (see GroupSize). If the budget is exceeded,
+// then a BudgetError is returned, otherwise the work group succeeds and
+// the errors that were tolerated are provided by the Failures method.
+// Errors that are context.Canceled, of workers that complete after
+// this manager cancels the work group context, are not included.
+func CancelOnErrorBudget(max int, percent float64) *ErrorBudget {
+	return &ErrorBudget{max: max, percent: percent}
+}
+
+// over returns true if the number of failures, n, is
+// over the budget for the total number of workers.
+func (m *ErrorBudget) over(n, total int) bool {
+	if m.max >= 0 && n > m.max {
+		return true
+	}
+	return m.percent >= 0 && total > 0 && float64(n) > m.percent*float64(total)/100
+}
+
+// Error returns a BudgetError if the budget has been exceeded.
+func (m *ErrorBudget) Error() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	total := m.size
+	if total == 0 {
+		total = m.ncomplete
+	}
+
+	if !m.exceeded && !m.over(len(m.errs), total) {
+		return nil
+	}
+
+	err := &BudgetError{Failed: len(m.errs), Total: total}
+	if merr := newMultiError(m.errs); merr != nil {
+		err.MultiError = *merr
+	}
+	return err
+}
+
+// Failures returns a MultiError holding the errors of the
+// workers that completed with an error, or nil if there are none.
+func (m *ErrorBudget) Failures() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := newMultiError(m.errs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Manage implements the Manager interface.
+func (m *ErrorBudget) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if size, ok := GroupSize(ctx); ok {
+		m.size = size
+	}
+
+	m.ncomplete++
+	if *err != nil {
+		if m.exceeded && errors.Is(*err, context.Canceled) {
+			return m.ncomplete
+		}
+		m.errs = append(m.errs, &IndexedError{Index: idx, Err: *err})
+		if !m.exceeded && m.over(len(m.errs), m.size) {
+			m.exceeded = true
+			c.Cancel()
+		}
+	}
+
+	return m.ncomplete
+}
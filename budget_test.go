@@ -0,0 +1,117 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorBudgetWithin(t *testing.T) {
+
+	var started int
+
+	m := CancelOnErrorBudget(3, -1)
+
+	err := WorkFor(context.Background(), NewLimited(1), m, 10,
+		func(ctx context.Context, index int) error {
+			started++
+			if index == 1 || index == 4 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if started != 10 {
+		t.Fatalf("Expecting 10 workers to start, got %d", started)
+	}
+
+	var merr *MultiError
+	if !errors.As(m.Failures(), &merr) || len(merr.Errors) != 2 {
+		t.Fatalf("Expecting 2 tolerated errors: %v", m.Failures())
+	}
+}
+
+func TestErrorBudgetExceeded(t *testing.T) {
+
+	var started int
+
+	err := WorkFor(context.Background(), NewLimited(1), CancelOnErrorBudget(1, -1), 10,
+		func(ctx context.Context, index int) error {
+			started++
+			if index%2 == 0 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	var berr *BudgetError
+	if !errors.As(err, &berr) {
+		t.Fatalf("Work group error is not a budget error: %v", err)
+	}
+
+	expected := "error budget exceeded with 2 of 10 failed:" +
+		"\n\t* worker 0: worker 0 failed" +
+		"\n\t* worker 2: worker 2 failed"
+	if err.Error() != expected {
+		t.Fatalf("Expecting error %q, got %q", expected, err.Error())
+	}
+
+	if started != 3 {
+		t.Fatalf("Expecting 3 workers to start, got %d", started)
+	}
+}
+
+func TestErrorBudgetPercent(t *testing.T) {
+
+	var started int
+
+	err := WorkFor(context.Background(), NewLimited(1), CancelOnErrorBudget(-1, 10), 20,
+		func(ctx context.Context, index int) error {
+			started++
+			if index < 3 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	var berr *BudgetError
+	if !errors.As(err, &berr) || berr.Failed != 3 || berr.Total != 20 {
+		t.Fatalf("Work group error is not a budget error with 3 of 20 failed: %v", err)
+	}
+
+	if started != 3 {
+		t.Fatalf("Expecting 3 workers to start, got %d", started)
+	}
+}
+
+func TestErrorBudgetPercentUnknownSize(t *testing.T) {
+
+	workers := make(chan Worker, 4)
+	for i := 0; i < 4; i++ {
+		index := i
+		workers <- func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				t.Errorf("Worker %d must not see a canceled context", index)
+			}
+			if index < 2 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		}
+	}
+	close(workers)
+
+	err := WorkChan(context.Background(), NewLimited(1), CancelOnErrorBudget(-1, 25), workers)
+
+	var berr *BudgetError
+	if !errors.As(err, &berr) || berr.Failed != 2 || berr.Total != 4 {
+		t.Fatalf("Work group error is not a budget error with 2 of 4 failed: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package workgroup
+
+import (
+	"time"
+)
+
+// clock provides the current time and timers,
+// so that it can be replaced when testing.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
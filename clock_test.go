@@ -0,0 +1,80 @@
+package workgroup
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a clock that only advances when requested.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward and fires any expired timers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			timers = append(timers, t)
+		} else {
+			t.ch <- c.now
+		}
+	}
+	c.timers = timers
+}
+
+// AdvanceNext moves the clock forward to the next timer and fires it.
+func (c *fakeClock) AdvanceNext() {
+	c.mutex.Lock()
+	var d time.Duration
+	for i, t := range c.timers {
+		if i == 0 || t.at.Sub(c.now) < d {
+			d = t.at.Sub(c.now)
+		}
+	}
+	c.mutex.Unlock()
+
+	c.Advance(d)
+}
+
+// Timers returns the number of timers that have not fired.
+func (c *fakeClock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
@@ -0,0 +1,138 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IndexedError is the error of the worker with the given index.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+func (e *IndexedError) Error() string {
+	return fmt.Sprintf("worker %d: %s", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the worker.
+func (e *IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is an error that holds the errors of
+// multiple workers of a work group, ordered by index.
+type MultiError struct {
+	Errors []*IndexedError
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return "1 error occurred:" + e.list()
+	}
+	return fmt.Sprintf("%d errors occurred:", len(e.Errors)) + e.list()
+}
+
+// list returns the errors with each on a separate line.
+func (e *MultiError) list() string {
+	var b strings.Builder
+	for _, err := range e.Errors {
+		b.WriteString("\n\t* ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the workers, as instances of
+// IndexedError, so that they can be found with errors.Is and errors.As.
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// newMultiError returns a MultiError with a copy of
+// the errors ordered by index, or nil if there are none.
+func newMultiError(errs []*IndexedError) *MultiError {
+	if len(errs) == 0 {
+		return nil
+	}
+	errs = append([]*IndexedError(nil), errs...)
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Index < errs[j].Index
+	})
+	return &MultiError{Errors: errs}
+}
+
+type collectAll struct {
+	mutex     sync.Mutex
+	cancel    bool
+	canceled  bool
+	ncomplete int
+	errs      []*IndexedError
+}
+
+// CollectOption is an option of the CollectAll manager.
+type CollectOption func(*collectAll)
+
+// CollectCancelOnFirstError is an option of the CollectAll manager
+// to cancel the work group context when a worker completes with an error.
+func CollectCancelOnFirstError() CollectOption {
+	return func(m *collectAll) {
+		m.cancel = true
+	}
+}
+
+// CollectCancelNever is an option of the CollectAll manager to never
+// cancel the work group context, which is the default.
+func CollectCancelNever() CollectOption {
+	return func(m *collectAll) {
+		m.cancel = false
+	}
+}
+
+// CollectAll initializes a new manager that returns a MultiError
+// holding the errors of all the workers that complete with an error.
+// Errors that are context.Canceled, of workers that complete after
+// this manager cancels the work group context, are not included.
+func CollectAll(opts ...CollectOption) Manager {
+	m := &collectAll{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *collectAll) Error() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := newMultiError(m.errs); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *collectAll) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.ncomplete++
+	if *err != nil {
+		if m.canceled && errors.Is(*err, context.Canceled) {
+			return m.ncomplete
+		}
+		m.errs = append(m.errs, &IndexedError{Index: idx, Err: *err})
+		if m.cancel && !m.canceled {
+			m.canceled = true
+			c.Cancel()
+		}
+	}
+
+	return m.ncomplete
+}
@@ -0,0 +1,90 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCollectAllNeverCancel(t *testing.T) {
+
+	errSentinel := errors.New("sentinel")
+
+	err := WorkFor(context.Background(), nil, CollectAll(), 10,
+		func(ctx context.Context, index int) error {
+			if ctx.Err() != nil {
+				t.Errorf("Worker %d must not see a canceled context", index)
+			}
+			switch index {
+			case 2, 7:
+				return fmt.Errorf("worker %d failed", index)
+			case 5:
+				return fmt.Errorf("worker %d failed: %w", index, errSentinel)
+			}
+			return nil
+		},
+	)
+
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("Work group error is not a multi error: %v", err)
+	}
+
+	expected := "3 errors occurred:" +
+		"\n\t* worker 2: worker 2 failed" +
+		"\n\t* worker 5: worker 5 failed: sentinel" +
+		"\n\t* worker 7: worker 7 failed"
+	if err.Error() != expected {
+		t.Fatalf("Expecting error %q, got %q", expected, err.Error())
+	}
+
+	if !errors.Is(err, errSentinel) {
+		t.Fatalf("Expecting work group error to be sentinel error")
+	}
+
+	var ierr *IndexedError
+	if !errors.As(err, &ierr) || ierr.Index != 2 {
+		t.Fatalf("Expecting work group error to be indexed error of worker 2: %v", ierr)
+	}
+}
+
+func TestCollectAllCancelOnFirstError(t *testing.T) {
+
+	var started int
+
+	err := WorkFor(context.Background(), NewLimited(1), CollectAll(CollectCancelOnFirstError()), 10,
+		func(ctx context.Context, index int) error {
+			started++
+			if index == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("Work group error is not a multi error: %v", err)
+	}
+
+	if len(merr.Errors) != 1 || merr.Errors[0].Index != 1 {
+		t.Fatalf("Expecting only the error of worker 1: %v", err)
+	}
+
+	if started != 2 {
+		t.Fatalf("Expecting 2 workers to start, got %d", started)
+	}
+}
+
+func TestCollectAllNoErrors(t *testing.T) {
+
+	err := WorkFor(context.Background(), nil, CollectAll(), 10,
+		func(ctx context.Context, index int) error {
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
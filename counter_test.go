@@ -45,7 +45,7 @@ func (c *atomicCounter) add(err error) (int, int, int) {
 		}
 
 		newCount := ncomplete
-		newCount |= nsuccess << atomicCounterNSuccessBits
+		newCount |= nsuccess << atomicCounterNSuccessShift
 		newCount |= nerror << atomicCounterNErrorShift
 
 		if atomic.CompareAndSwapUint64(&c.count, count, newCount) {
@@ -3,6 +3,7 @@ package workgroup
 import (
 	"context"
 	"runtime"
+	"sync"
 )
 
 // DefaultLimit is used for limited and pool executers
@@ -18,6 +19,39 @@ type Executer interface {
 	Execute(ctx context.Context, f func(ctx context.Context))
 }
 
+// Waiter is an optional interface that an Executer may implement to
+// make use of a goroutine that is waiting for a work group to complete,
+// for example, to execute other functions while it waits. The Wait method
+// must return once the done channel is closed. The context is that of the
+// work group, which may be canceled before the work group completes.
+type Waiter interface {
+	Wait(ctx context.Context, done <-chan struct{})
+}
+
+// ExecuterFunc is a function type that implements the Executer interface.
+type ExecuterFunc func(ctx context.Context, f func(ctx context.Context))
+
+// Execute calls the underlying function to execute.
+func (e ExecuterFunc) Execute(ctx context.Context, f func(ctx context.Context)) {
+	e(ctx, f)
+}
+
+type rejectKey struct{}
+
+// Reject is used by an executer that will not execute
+// function, f. It calls f on the current goroutine, but
+// the worker is skipped and the error, err, is given
+// to the manager in place of the error from the worker.
+func Reject(ctx context.Context, f func(context.Context), err error) {
+	f(context.WithValue(ctx, rejectKey{}, err))
+}
+
+// rejected returns the error given to Reject, if any.
+func rejected(ctx context.Context) error {
+	err, _ := ctx.Value(rejectKey{}).(error)
+	return err
+}
+
 type unlimited struct{}
 
 // NewUnlimited returns a executer that will execute functions
@@ -36,7 +70,9 @@ type limited struct {
 
 // NewLimited returns an executer that will execute functions
 // on at most, n, goroutines simultaneously. If n <= 0 then
-// the value provided by DefaultLimit will be used.
+// the value provided by DefaultLimit will be used. If the
+// context is done while waiting for a goroutine to become
+// available, then the function is rejected with the context error.
 func NewLimited(n int) Executer {
 	if n <= 0 {
 		n = DefaultLimit
@@ -49,8 +85,18 @@ func NewLimited(n int) Executer {
 	}
 }
 
-func (l *limited) add() {
-	l.ch <- struct{}{}
+func (l *limited) add(ctx context.Context) error {
+	// Check the context first, so that work is
+	// not started after the context is done.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case l.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (l *limited) done() {
@@ -58,51 +104,181 @@ func (l *limited) done() {
 }
 
 func (l *limited) Execute(ctx context.Context, f func(context.Context)) {
-	l.add()
+	if err := l.add(ctx); err != nil {
+		Reject(ctx, f, err)
+		return
+	}
 	go func() {
 		defer l.done()
 		f(ctx)
 	}()
 }
 
-type pool struct {
-	ch chan func()
+type inline struct{}
+
+// NewInline returns an executer that will execute functions
+// on the goroutine that submits them, before returning, so
+// that workers are executed one at a time in the order that
+// they are submitted. This is useful for deterministic testing
+// and debugging, as the manager is called before the next worker
+// starts, which will then see a canceled context. Workers must
+// not wait for each other, and for WorkChan, the goroutine
+// receiving from the channel executes the workers, so that the
+// producer can not send another worker until each one completes.
+func NewInline() Executer {
+	return &inline{}
 }
 
-// NewPool initializes a new pool executer that will execute
-// functions on fixed number of goroutines. If n <= 0 then
-// the values in DefaultLimit is used. Note that the provided
-// context must be cancelled to ensure that the pool releases
-// all resources.
-func NewPool(ctx context.Context, n int) Executer {
-	if n <= 0 {
-		n = DefaultLimit
+func (i *inline) Execute(ctx context.Context, f func(context.Context)) {
+	f(ctx)
+}
+
+type sequentialKey struct{}
+
+type sequential struct {
+	mutex   sync.Mutex
+	queue   []task
+	running bool
+	waiting bool
+	avail   chan struct{}
+	base    *sequentialSession
+	holder  *sequentialSession
+	changed chan struct{}
+}
+
+// sequentialSession identifies a goroutine that is executing functions
+// for a sequential executer, either its own goroutine, or one that is
+// waiting for a nested work group. Only the goroutines executing the
+// functions of the current holder may start a new session.
+type sequentialSession struct {
+	s *sequential
+}
+
+// NewSequential returns an executer that will execute functions one
+// at a time, in the order that they are submitted, on a goroutine
+// that is separate from the one that submits them. Unlike NewInline,
+// the producer for WorkChan continues to run while workers execute,
+// but workers must still not wait for each other. Nested work groups
+// are supported, since the goroutine executes queued functions while
+// it waits for a nested group to complete.
+func NewSequential() Executer {
+	s := &sequential{changed: make(chan struct{})}
+	s.base = &sequentialSession{s: s}
+	s.holder = s.base
+	return s
+}
+
+func (s *sequential) Execute(ctx context.Context, f func(context.Context)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.queue = append(s.queue, task{ctx: ctx, f: f})
+	if s.waiting {
+		s.waiting = false
+		close(s.avail)
 	}
-	if n <= 0 {
-		n = runtime.NumCPU()
+	if !s.running {
+		s.running = true
+		go s.run()
 	}
+}
 
-	p := &pool{
-		ch: make(chan func()),
+// Wait executes queued functions, if it is called from the goroutine of
+// this executer, until the done channel is closed. See the Waiter interface.
+// Since the goroutine is identified by its context, which may have been
+// inherited by other goroutines, only one goroutine at a time executes
+// functions, while the others wait.
+func (s *sequential) Wait(ctx context.Context, done <-chan struct{}) {
+	ss, ok := ctx.Value(sequentialKey{}).(*sequentialSession)
+	if !ok || ss.s != s {
+		return
 	}
 
-	if ctx != nil {
-		go func() {
-			<-ctx.Done()
-			close(p.ch)
-		}()
+	var nested *sequentialSession
+	for {
+		var changed <-chan struct{}
+		if nested, changed = s.hold(ss); nested != nil {
+			break
+		}
+		select {
+		case <-done:
+			return
+		case <-changed:
+		}
 	}
 
-	for i := 0; i < n; i++ {
-		go func() {
-			for f := range p.ch {
-				f()
+	defer func() {
+		s.mutex.Lock()
+		s.holder = ss
+		close(s.changed)
+		s.changed = make(chan struct{})
+		s.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		s.mutex.Lock()
+		if len(s.queue) == 0 {
+			// The remaining workers of the nested group
+			// have not yet been submitted to the queue.
+			if !s.waiting {
+				s.waiting = true
+				s.avail = make(chan struct{})
 			}
-		}()
+			avail := s.avail
+			s.mutex.Unlock()
+
+			select {
+			case <-done:
+			case <-avail:
+			}
+			continue
+		}
+		t := s.pop()
+		s.mutex.Unlock()
+
+		t.f(context.WithValue(t.ctx, sequentialKey{}, nested))
+	}
+}
+
+// hold starts a nested session, if the session is the holder, otherwise
+// it returns a channel that is closed when the holder changes.
+func (s *sequential) hold(ss *sequentialSession) (*sequentialSession, <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.holder != ss {
+		return nil, s.changed
+	}
+	s.holder = &sequentialSession{s: s}
+	return s.holder, nil
+}
+
+func (s *sequential) run() {
+	for {
+		s.mutex.Lock()
+		if len(s.queue) == 0 {
+			s.running = false
+			s.mutex.Unlock()
+			return
+		}
+		t := s.pop()
+		s.mutex.Unlock()
+
+		t.f(context.WithValue(t.ctx, sequentialKey{}, s.base))
 	}
-	return p
 }
 
-func (p *pool) Execute(ctx context.Context, f func(context.Context)) {
-	p.ch <- func() { f(ctx) }
+// pop removes the next task from the queue,
+// and must be called with the mutex locked.
+func (s *sequential) pop() task {
+	t := s.queue[0]
+	s.queue[0] = task{}
+	s.queue = s.queue[1:]
+	return t
 }
@@ -0,0 +1,221 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimitedCancelOnFirstError(t *testing.T) {
+
+	var started int64
+
+	m := &AccumulateManager{
+		manager: CancelOnFirstError(),
+	}
+
+	err := WorkFor(context.Background(), NewLimited(1), m, 10000,
+		func(ctx context.Context, index int) error {
+			atomic.AddInt64(&started, 1)
+			return fmt.Errorf("worker %d failed", index)
+		},
+	)
+
+	if err == nil || err.Error() != "worker 0 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	if started != 1 {
+		t.Fatalf("Expecting only 1 worker to start, got %d", started)
+	}
+
+	if len(m.Errors) != 10000 {
+		t.Fatalf("Expecting 10000 workers to be managed, got %d", len(m.Errors))
+	}
+	for i, e := range m.Errors[1:] {
+		if e != context.Canceled {
+			t.Fatalf("Expecting accumulated error (%d) to be canceled: %v", i+1, e)
+		}
+	}
+}
+
+func TestLimitedCanceledContext(t *testing.T) {
+
+	var started int64
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := WorkFor(ctx, NewLimited(1), CancelNeverFirstError(), 10000,
+		func(ctx context.Context, index int) error {
+			atomic.AddInt64(&started, 1)
+			return nil
+		},
+	)
+
+	if err != context.Canceled {
+		t.Fatalf("Work group error is not canceled: %v", err)
+	}
+
+	if started != 0 {
+		t.Fatalf("Expecting no workers to start, got %d", started)
+	}
+}
+
+func TestReject(t *testing.T) {
+
+	rejectErr := fmt.Errorf("rejected")
+
+	e := ExecuterFunc(func(ctx context.Context, f func(context.Context)) {
+		Reject(ctx, f, rejectErr)
+	})
+
+	err := WorkFor(context.Background(), e, CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			t.Errorf("Worker %d must not start", index)
+			return nil
+		},
+	)
+
+	if err != rejectErr {
+		t.Fatalf("Work group error is not rejected error: %v", err)
+	}
+}
+
+func TestInlineCancelOnFirstError(t *testing.T) {
+
+	var order []int
+
+	err := WorkFor(context.Background(), NewInline(), CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			order = append(order, index)
+			if index < 3 && ctx.Err() != nil {
+				t.Errorf("Worker %d must not see a canceled context", index)
+			}
+			if index > 3 && ctx.Err() == nil {
+				t.Errorf("Worker %d must see a canceled context", index)
+			}
+			if index == 3 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	if err == nil || err.Error() != "worker 3 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	for i, index := range order {
+		if i != index {
+			t.Fatalf("Workers are not executed in order: %v", order)
+		}
+	}
+}
+
+func TestSequentialOrder(t *testing.T) {
+
+	var running int64
+	var order []int
+
+	e := NewSequential()
+
+	workers := make(chan Worker)
+	go func() {
+		defer close(workers)
+		for i := 0; i < 1000; i++ {
+			index := i
+			workers <- func(ctx context.Context) error {
+				if atomic.AddInt64(&running, 1) != 1 {
+					t.Errorf("Worker %d is not running sequentially", index)
+				}
+				defer atomic.AddInt64(&running, -1)
+				order = append(order, index)
+				return nil
+			}
+		}
+	}()
+
+	if err := WorkChan(context.Background(), e, CancelOnFirstError(), workers); err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	for i, index := range order {
+		if i != index {
+			t.Fatalf("Workers are not executed in order: %v", order)
+		}
+	}
+}
+
+func TestSequentialNested(t *testing.T) {
+
+	var order []string
+
+	e := NewSequential()
+
+	record := func(name string) Worker {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	err := Work(context.Background(), e, CancelOnFirstError(),
+		Group(e, CancelOnFirstError(),
+			Group(e, CancelOnFirstError(), record("a1"), record("a2")),
+			record("a3"),
+		),
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	// The nested group is executed after the
+	// workers that were submitted before it.
+	expected := []string{"a3", "a1", "a2"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Fatalf("Expecting order %v, got %v", expected, order)
+	}
+}
+
+// TestSequentialNestedUnlimited is a regression test for goroutines,
+// that are not of the executer, but inherit the context of its goroutine,
+// executing functions at the same time as each other.
+func TestSequentialNestedUnlimited(t *testing.T) {
+
+	var running, peak int64
+
+	e := NewSequential()
+
+	err := WorkFor(context.Background(), e, CancelOnFirstError(), 2,
+		func(ctx context.Context, i int) error {
+			return WorkFor(ctx, NewUnlimited(), CancelOnFirstError(), 8,
+				func(ctx context.Context, j int) error {
+					return WorkFor(ctx, e, CancelOnFirstError(), 4,
+						func(ctx context.Context, k int) error {
+							r := atomic.AddInt64(&running, 1)
+							defer atomic.AddInt64(&running, -1)
+							for {
+								m := atomic.LoadInt64(&peak)
+								if r <= m || atomic.CompareAndSwapInt64(&peak, m, r) {
+									break
+								}
+							}
+							time.Sleep(time.Millisecond)
+							return nil
+						},
+					)
+				},
+			)
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if peak != 1 {
+		t.Fatalf("Expecting a maximum of 1 worker running, got %d", peak)
+	}
+}
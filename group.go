@@ -0,0 +1,279 @@
+package workgroup
+
+import (
+	"context"
+	"runtime/trace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// group holds the state shared by all the workers of a work group.
+type group struct {
+	pending   int64 // first for alignment of atomic operations
+	ctx       context.Context
+	cancel    context.CancelFunc
+	e         Executer
+	m         Manager
+	labels    *labelSet
+	task      *trace.Task
+	tracer    Tracer
+	span      Span
+	obs       observers
+	start     time.Time
+	cancelled int32
+	watched   chan struct{}
+	size      int
+	done      chan struct{}
+}
+
+type workerKey struct{}
+
+// workerInfo is provided in the context of a worker.
+type workerInfo struct {
+	index   int
+	size    int
+	started time.Time
+}
+
+// workerOf returns the information about the worker of the context, if any.
+func workerOf(ctx context.Context) (*workerInfo, bool) {
+	info, ok := ctx.Value(workerKey{}).(*workerInfo)
+	return info, ok
+}
+
+// GroupSize returns the number of workers in the work group of
+// the context of a worker, including the context that is given to
+// a manager. The size is only known for work groups that are started
+// with Work or WorkFor, and otherwise false is returned.
+func GroupSize(ctx context.Context) (int, bool) {
+	if info, ok := workerOf(ctx); ok && info.size > 0 {
+		return info.size, true
+	}
+	return 0, false
+}
+
+func newGroup(ctx context.Context, e Executer, m Manager) *group {
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	if e == nil {
+		e = DefaultExecuter()
+	}
+
+	if m == nil {
+		m = DefaultManager()
+	}
+
+	// The pending count includes the goroutine that
+	// is submitting workers, until wait is called.
+	g := &group{e: e, m: m, labels: labelsOf(ctx), pending: 1, done: make(chan struct{})}
+	if name, ok := traceTaskOf(ctx); ok {
+		ctx, g.task = trace.NewTask(ctx, name)
+	}
+	if g.tracer = tracerOf(ctx); g.tracer != nil {
+		ctx, g.span = g.tracer.Start(ctx, "workgroup")
+	}
+	if g.obs = observersOf(ctx); g.obs != nil {
+		// Observers are not inherited by nested work groups.
+		ctx = context.WithValue(ctx, observerKey{}, observers(nil))
+		g.start = time.Now()
+	}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	if g.obs != nil && ctx.Done() != nil {
+		g.watched = make(chan struct{})
+		go g.watch(ctx)
+	}
+	return g
+}
+
+// watch notifies the observers if the parent context, ctx,
+// is done before all the workers of the group complete.
+func (g *group) watch(ctx context.Context) {
+	defer close(g.watched)
+	select {
+	case <-ctx.Done():
+		if atomic.CompareAndSwapInt32(&g.cancelled, 0, 1) {
+			g.obs.OnCancel(g.ctx, -1, ctx.Err())
+		}
+	case <-g.done:
+	}
+}
+
+// finish decrements the pending count, and closes
+// the done channel when the count reaches zero.
+func (g *group) finish() {
+	if atomic.AddInt64(&g.pending, -1) == 0 {
+		close(g.done)
+	}
+}
+
+// execute arranges for the worker, w, to be executed with the given index.
+func (g *group) execute(index int, w IdxWorker) {
+	atomic.AddInt64(&g.pending, 1)
+
+	var submitted time.Time
+	if g.obs != nil {
+		g.obs.OnSubmit(g.ctx, index)
+		submitted = time.Now()
+	}
+
+	g.e.Execute(g.ctx, func(ctx context.Context) {
+		defer g.finish()
+
+		handler, recovers := recoverPanicsOf(ctx)
+		if recovers {
+			// The handler is not inherited by nested work groups.
+			ctx = context.WithValue(ctx, recoverPanicsKey{}, nil)
+		}
+
+		info := &workerInfo{index: index, size: g.size, started: time.Now()}
+		ctx = context.WithValue(ctx, workerKey{}, info)
+
+		var err error
+		c := Canceller(CancellerFunc(g.cancel))
+		if g.obs != nil {
+			defer g.observeWorker(ctx, info, submitted, &err)()
+			c = g.observeCancel(ctx, index, &err)
+		}
+		if g.task != nil {
+			defer traceWorker(ctx, index, &err)()
+		}
+		if g.tracer != nil {
+			var end func()
+			ctx, end = startWorkerSpan(ctx, g.tracer, index, &err)
+			defer end()
+		}
+		defer g.m.Manage(ctx, c, index, &err)
+		if recovers {
+			defer g.recoverPanic(ctx, handler, &err)
+		}
+		if err = rejected(ctx); err != nil {
+			return
+		}
+		if g.labels != nil {
+			g.labels.do(ctx, index, func(ctx context.Context) {
+				err = w(ctx, index)
+			})
+			return
+		}
+		err = w(ctx, index)
+	})
+}
+
+// wait waits for all workers to complete, then cancels
+// the work group context and returns the final error.
+// If the executer is a Waiter then it is used to wait.
+// The work group is ended, even if the manager panics, and
+// the panic is recorded as a PanicError.
+func (g *group) wait() (err error) {
+	g.finish()
+	if w, ok := g.e.(Waiter); ok {
+		w.Wait(g.ctx, g.done)
+	}
+	<-g.done
+	if g.watched != nil {
+		<-g.watched
+	}
+	g.cancel()
+	defer func() {
+		// The manager may panic, if it is wrapped with Repanic,
+		// in which case the panic is recorded and continued.
+		v := recover()
+		if v != nil {
+			err = &PanicError{Value: v}
+		}
+		if g.task != nil {
+			traceGroup(g.ctx, g.task, err)
+		}
+		if g.span != nil {
+			endGroupSpan(g.span, err)
+		}
+		if g.obs != nil {
+			g.obs.OnGroupDone(g.ctx, time.Since(g.start), err)
+		}
+		if v != nil {
+			panic(v)
+		}
+	}()
+	return g.m.Error()
+}
+
+// recoverPanic recovers a panic of the worker, before its error is given
+// to the manager, and replaces the error with a PanicError. It must be
+// called directly by defer.
+func (g *group) recoverPanic(ctx context.Context, handler func(ctx context.Context, v interface{}), err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Value: v}
+		if handler != nil {
+			handler(ctx, v)
+		}
+	}
+}
+
+// observeWorker notifies the observers that the worker has started,
+// unless it is rejected, and returns a function that notifies the
+// observers that it has finished.
+func (g *group) observeWorker(ctx context.Context, info *workerInfo, submitted time.Time, err *error) func() {
+	if rejected(ctx) == nil {
+		g.obs.OnStart(ctx, info.index, info.started.Sub(submitted))
+	}
+	return func() {
+		g.obs.OnFinish(ctx, info.index, time.Since(info.started), *err)
+	}
+}
+
+// observeCancel returns a canceller that cancels the work group and
+// notifies the observers the first time that the work group is canceled.
+func (g *group) observeCancel(ctx context.Context, index int, err *error) Canceller {
+	return CancellerFunc(func() {
+		if atomic.CompareAndSwapInt32(&g.cancelled, 0, 1) {
+			g.obs.OnCancel(ctx, index, *err)
+		}
+		g.cancel()
+	})
+}
+
+// WorkGroup is a work group to which workers can be added
+// incrementally. It provides the same guarantees as Work(),
+// but the workers do not need to be known in advance.
+type WorkGroup struct {
+	g     *group
+	mutex sync.Mutex
+	n     int
+}
+
+// NewWorkGroup initializes a new work group. The executer, e,
+// and manager, m, have the same meaning as for Work(), including
+// the defaults used if they are not provided.
+func NewWorkGroup(ctx context.Context, e Executer, m Manager) *WorkGroup {
+	return &WorkGroup{g: newGroup(ctx, e, m)}
+}
+
+// Context returns the work group context, which is canceled
+// when the manager cancels the work group or Wait() returns.
+func (w *WorkGroup) Context() context.Context {
+	return w.g.ctx
+}
+
+// Go arranges for the worker, wkr, to be executed. Workers are
+// assigned indexes in the order that they are added. It is safe
+// to call Go from within a worker of this group, but Go must not
+// be called after Wait has returned.
+func (w *WorkGroup) Go(wkr Worker) {
+	w.mutex.Lock()
+	index := w.n
+	w.n++
+	w.mutex.Unlock()
+
+	w.g.execute(index, func(ctx context.Context, _ int) error {
+		return wkr(ctx)
+	})
+}
+
+// Wait waits for all the workers of this group to complete
+// and returns the error provided by the manager.
+func (w *WorkGroup) Wait() error {
+	return w.g.wait()
+}
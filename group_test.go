@@ -0,0 +1,100 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSimpleWorkGroup(t *testing.T) {
+
+	counts := make([]int, 10000)
+
+	g := NewWorkGroup(nil, nil, nil)
+	for i := 0; i < len(counts); i++ {
+		index := i
+		g.Go(func(ctx context.Context) error {
+			time.Sleep(time.Millisecond)
+			counts[index]++
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	for _, c := range counts {
+		if c != 1 {
+			t.Errorf("Worker %d has not completed", c)
+		}
+	}
+}
+
+func TestRecursiveWorkGroup(t *testing.T) {
+
+	var count int64
+
+	g := NewWorkGroup(context.Background(), NewUnlimited(), CancelNeverFirstError())
+
+	var walk func(depth int) Worker
+	walk = func(depth int) Worker {
+		return func(ctx context.Context) error {
+			atomic.AddInt64(&count, 1)
+			if depth < 10 {
+				g.Go(walk(depth + 1))
+				g.Go(walk(depth + 1))
+			}
+			return nil
+		}
+	}
+
+	g.Go(walk(0))
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if count != 2047 {
+		t.Fatalf("Expecting 2047 workers to complete, got %d", count)
+	}
+}
+
+func TestCancelOnFirstErrorWorkGroup(t *testing.T) {
+
+	m := &AccumulateManager{
+		manager: CancelOnFirstError(),
+	}
+
+	g := NewWorkGroup(context.Background(), NewUnlimited(), m)
+	for i := 0; i < 1000; i++ {
+		index := i
+		g.Go(func(ctx context.Context) error {
+			if index == 500 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	err := g.Wait()
+	if err == nil || err.Error() != "worker 500 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	if g.Context().Err() == nil {
+		t.Fatalf("Work group context is not canceled")
+	}
+
+	if len(m.Errors) != 1000 {
+		t.Fatalf("Expecting 1000 workers to be managed, got %d", len(m.Errors))
+	}
+	for i, e := range m.Errors[1:] {
+		if e != context.Canceled {
+			t.Fatalf("Expecting accumulated error (%d) to be canceled: %v", i+1, e)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type keyKey struct{}
+
+// WithKey returns a copy of the context with the given key, which
+// is used by the keyed executer to execute functions serially.
+// The key must be comparable.
+func WithKey(ctx context.Context, key interface{}) context.Context {
+	return context.WithValue(ctx, keyKey{}, key)
+}
+
+// Key returns the key of the context, and false
+// if a key has not been set with WithKey.
+func Key(ctx context.Context) (interface{}, bool) {
+	key := ctx.Value(keyKey{})
+	return key, key != nil
+}
+
+type keyed struct {
+	mutex  sync.Mutex
+	inner  Executer
+	queues map[interface{}][]task
+}
+
+// NewKeyed returns an executer that executes functions with the same
+// key (see WithKey) serially, in the order that they were submitted,
+// while functions with different keys are executed in parallel by the
+// inner executer. The functions for a key are executed one after the
+// other on the same goroutine of the inner executer, and a queued
+// function with a context that is done is rejected with the context
+// error. Functions without a key are passed to the inner executer.
+// If inner is not provided then DefaultExecuter is called to obtain
+// the default.
+func NewKeyed(inner Executer) Executer {
+	if inner == nil {
+		inner = DefaultExecuter()
+	}
+	return &keyed{
+		inner:  inner,
+		queues: make(map[interface{}][]task),
+	}
+}
+
+func (k *keyed) Execute(ctx context.Context, f func(context.Context)) {
+	key, ok := Key(ctx)
+	if !ok {
+		k.inner.Execute(ctx, f)
+		return
+	}
+
+	k.mutex.Lock()
+	if q, active := k.queues[key]; active {
+		k.queues[key] = append(q, task{ctx: ctx, f: f})
+		k.mutex.Unlock()
+		return
+	}
+	k.queues[key] = nil
+	k.mutex.Unlock()
+
+	k.submit(key, task{ctx: ctx, f: f})
+}
+
+// submit passes the task to the inner executer, and when it is executed,
+// the queued tasks for the key are then executed on the same goroutine.
+// If the inner executer rejects the task while it is being submitted,
+// then the next task for the key is submitted by this loop, so that the
+// stack does not grow with the length of the queue, and if it rejects
+// the task later, then the next task is submitted on a new goroutine.
+func (k *keyed) submit(key interface{}, t task) {
+	for {
+		// The state is 0 while the task is being submitted,
+		// 1 once it has been submitted, and 2 if it is rejected
+		// while it is being submitted.
+		var state int32
+		cur := t
+		k.inner.Execute(cur.ctx, func(ctx context.Context) {
+			cur.f(ctx)
+
+			if rejected(ctx) != nil {
+				if atomic.CompareAndSwapInt32(&state, 0, 2) {
+					return
+				}
+				// The inner executer rejected the task after it was
+				// submitted, so submit the next task on a new goroutine,
+				// since this may be a goroutine of the inner executer.
+				if t, ok := k.next(key); ok {
+					go k.submit(key, t)
+				}
+				return
+			}
+
+			for {
+				t, ok := k.next(key)
+				if !ok {
+					return
+				}
+				if err := t.ctx.Err(); err != nil {
+					Reject(t.ctx, t.f, err)
+				} else {
+					t.f(t.ctx)
+				}
+			}
+		})
+
+		if atomic.CompareAndSwapInt32(&state, 0, 1) {
+			return
+		}
+
+		var ok bool
+		if t, ok = k.next(key); !ok {
+			return
+		}
+	}
+}
+
+// next removes the next task from the queue for the key, if there are
+// no tasks remaining then the queue is removed and it returns false.
+func (k *keyed) next(key interface{}) (task, bool) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	q := k.queues[key]
+	if len(q) == 0 {
+		delete(k.queues, key)
+		return task{}, false
+	}
+
+	t := q[0]
+	q[0] = task{}
+	k.queues[key] = q[1:]
+	return t, true
+}
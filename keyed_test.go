@@ -0,0 +1,269 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyedOrder(t *testing.T) {
+
+	var mutex sync.Mutex
+	order := make(map[int][]int)
+	running := make([]int64, 10)
+
+	e := NewKeyed(NewLimited(4))
+	k := e.(*keyed)
+
+	err := WorkFor(context.Background(), nil, CancelOnFirstError(), len(running),
+		func(ctx context.Context, key int) error {
+			return WorkFor(WithKey(ctx, key), e, CancelOnFirstError(), 100,
+				func(ctx context.Context, index int) error {
+					if atomic.AddInt64(&running[key], 1) != 1 {
+						t.Errorf("Worker %d for key %d is not running serially", index, key)
+					}
+					defer atomic.AddInt64(&running[key], -1)
+
+					time.Sleep(100 * time.Microsecond)
+
+					mutex.Lock()
+					defer mutex.Unlock()
+					order[key] = append(order[key], index)
+					return nil
+				},
+			)
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	for key, indexes := range order {
+		for i, index := range indexes {
+			if i != index {
+				t.Fatalf("Workers for key %d are not in order: %v", key, indexes)
+			}
+		}
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if len(k.queues) != 0 {
+		t.Fatalf("Expecting all queues to be removed, got %d", len(k.queues))
+	}
+}
+
+func TestKeyedParallel(t *testing.T) {
+
+	a := make(chan struct{})
+	b := make(chan struct{})
+
+	e := NewKeyed(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := Work(ctx, nil, CancelOnFirstError(),
+		func(ctx context.Context) error {
+			return Work(WithKey(ctx, "a"), e, CancelOnFirstError(), func(ctx context.Context) error {
+				close(a)
+				select {
+				case <-b:
+					return nil
+				case <-ctx.Done():
+					return fmt.Errorf("key a is not parallel with key b")
+				}
+			})
+		},
+		func(ctx context.Context) error {
+			return Work(WithKey(ctx, "b"), e, CancelOnFirstError(), func(ctx context.Context) error {
+				close(b)
+				select {
+				case <-a:
+					return nil
+				case <-ctx.Done():
+					return fmt.Errorf("key b is not parallel with key a")
+				}
+			})
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
+
+func TestKeyedCanceled(t *testing.T) {
+
+	e := NewKeyed(nil)
+	k := e.(*keyed)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Execute(WithKey(context.Background(), "key"), func(ctx context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(WithKey(context.Background(), "key"))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WorkFor(ctx, e, CancelNeverFirstError(), 100,
+			func(ctx context.Context, index int) error {
+				t.Errorf("Worker %d must not start", index)
+				return nil
+			},
+		)
+	}()
+
+	for {
+		k.mutex.Lock()
+		n := len(k.queues["key"])
+		k.mutex.Unlock()
+		if n == 100 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	close(release)
+
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Work group error is not canceled: %v", err)
+	}
+
+	for {
+		k.mutex.Lock()
+		n := len(k.queues)
+		k.mutex.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestKeyedRejected(t *testing.T) {
+
+	p := NewQueuedPool(nil, 1, 1, BlockPolicy)
+	e := NewKeyed(p)
+
+	release := blockExecuter(p)
+
+	m := &depthManager{Manager: CancelNeverFirstError()}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WorkFor(WithKey(context.Background(), "key"), e, m, 100,
+			func(ctx context.Context, index int) error {
+				t.Errorf("Worker %d must not start", index)
+				return nil
+			},
+		)
+	}()
+
+	k := e.(*keyed)
+	for {
+		k.mutex.Lock()
+		n := len(k.queues["key"])
+		k.mutex.Unlock()
+		if n == 99 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	p.Close()
+	release()
+
+	if err := <-done; err != ErrExecuterClosed {
+		t.Fatalf("Work group error is not closed error: %v", err)
+	}
+
+	// The queued workers must be rejected without recursion
+	if m.depth >= 50 {
+		t.Fatalf("Expecting rejected workers to be managed without recursion, got stack depth %d", m.depth)
+	}
+}
+
+// TestKeyedRejectedFullQueue is a regression test for the next task for
+// a key being submitted from the goroutine of a pool, that rejected the
+// previous task, and waiting for space in the queue that only it can free.
+func TestKeyedRejectedFullQueue(t *testing.T) {
+
+	p := NewQueuedPool(nil, 1, 1, BlockPolicy)
+	defer p.Close()
+
+	e := NewKeyed(p)
+
+	release := blockExecuter(p)
+
+	ctx, cancel := context.WithCancel(WithKey(context.Background(), "key"))
+
+	// The queue is filled again after the pool takes the canceled task.
+	hc := &hookContext{Context: ctx, hook: func() {
+		p.Execute(context.Background(), func(ctx context.Context) {})
+	}}
+
+	rejected := make(chan error, 1)
+	e.Execute(hc, func(ctx context.Context) {
+		rejected <- Rejected(ctx)
+	})
+
+	done := make(chan struct{})
+	e.Execute(WithKey(context.Background(), "key"), func(ctx context.Context) {
+		close(done)
+	})
+
+	cancel()
+	release()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Next task for the key is deadlocked")
+	}
+
+	if err := <-rejected; err != context.Canceled {
+		t.Fatalf("Expecting canceled task to be rejected with canceled error: %v", err)
+	}
+}
+
+// hookContext calls hook the first time that Err returns an error.
+type hookContext struct {
+	context.Context
+	once sync.Once
+	hook func()
+}
+
+func (c *hookContext) Err() error {
+	err := c.Context.Err()
+	if err != nil {
+		c.once.Do(c.hook)
+	}
+	return err
+}
+
+// depthManager records the maximum stack depth at which workers are managed.
+type depthManager struct {
+	Manager
+	mutex sync.Mutex
+	depth int
+}
+
+func (m *depthManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	pc := make([]uintptr, 1000)
+	n := runtime.Callers(0, pc)
+	m.mutex.Lock()
+	if n > m.depth {
+		m.depth = n
+	}
+	m.mutex.Unlock()
+	return m.Manager.Manage(ctx, c, idx, err)
+}
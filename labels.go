@@ -0,0 +1,39 @@
+package workgroup
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+type labelsKey struct{}
+
+type labelSet struct {
+	name   string
+	labels []string
+}
+
+// WithLabels returns a copy of the context with which the workers of
+// a work group are executed using pprof.Do, so that profiles can be
+// filtered by work group. The labels include "workgroup", with the
+// value of name, and "worker", with the worker index, as well as the
+// given labels, which are pairs of keys and values as for pprof.Labels.
+// Nested work groups use the same labels, unless they are replaced.
+func WithLabels(ctx context.Context, name string, labels ...string) context.Context {
+	if len(labels)%2 == 1 {
+		panic("workgroup: odd number of label arguments")
+	}
+	return context.WithValue(ctx, labelsKey{}, &labelSet{name: name, labels: labels})
+}
+
+// labelsOf returns the labels of the context, or nil if there are none.
+func labelsOf(ctx context.Context) *labelSet {
+	l, _ := ctx.Value(labelsKey{}).(*labelSet)
+	return l
+}
+
+// do calls the function, f, using pprof.Do with the labels for the worker.
+func (l *labelSet) do(ctx context.Context, index int, f func(context.Context)) {
+	labels := append([]string{"workgroup", l.name, "worker", strconv.Itoa(index)}, l.labels...)
+	pprof.Do(ctx, pprof.Labels(labels...), f)
+}
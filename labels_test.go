@@ -0,0 +1,61 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strconv"
+	"testing"
+)
+
+func TestWithLabels(t *testing.T) {
+
+	ctx := WithLabels(context.Background(), "outer", "job", "test")
+
+	err := WorkFor(ctx, NewLimited(2), CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			if v, _ := pprof.Label(ctx, "workgroup"); v != "outer" {
+				return fmt.Errorf("worker %d workgroup label is %q", index, v)
+			}
+			if v, _ := pprof.Label(ctx, "worker"); v != strconv.Itoa(index) {
+				return fmt.Errorf("worker %d worker label is %q", index, v)
+			}
+			if v, _ := pprof.Label(ctx, "job"); v != "test" {
+				return fmt.Errorf("worker %d job label is %q", index, v)
+			}
+
+			return WorkFor(WithLabels(ctx, "inner"), nil, CancelOnFirstError(), 2,
+				func(ctx context.Context, nested int) error {
+					if v, _ := pprof.Label(ctx, "workgroup"); v != "inner" {
+						return fmt.Errorf("nested worker %d workgroup label is %q", nested, v)
+					}
+					if v, _ := pprof.Label(ctx, "worker"); v != strconv.Itoa(nested) {
+						return fmt.Errorf("nested worker %d worker label is %q", nested, v)
+					}
+					if v, _ := pprof.Label(ctx, "job"); v != "test" {
+						return fmt.Errorf("nested worker %d job label is %q", nested, v)
+					}
+					return nil
+				},
+			)
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
+
+func TestWithoutLabels(t *testing.T) {
+
+	err := WorkFor(context.Background(), nil, CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			if v, ok := pprof.Label(ctx, "workgroup"); ok {
+				return fmt.Errorf("worker %d workgroup label is %q", index, v)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
@@ -0,0 +1,79 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// LogLevels are the levels used to log the outcomes of workers.
+type LogLevels struct {
+	Success  slog.Level // worker completed without error
+	Error    slog.Level // worker completed with an error, or panicked
+	Canceled slog.Level // worker completed with context.Canceled
+	Cancel   slog.Level // manager canceled the work group
+}
+
+// DefaultLogLevels are the levels used by Log.
+var DefaultLogLevels = LogLevels{
+	Success:  slog.LevelDebug,
+	Error:    slog.LevelError,
+	Canceled: slog.LevelDebug,
+	Cancel:   slog.LevelInfo,
+}
+
+type logWrapper struct {
+	m      Manager
+	logger *slog.Logger
+	levels LogLevels
+}
+
+// Log wraps a Manager, m, and logs the completion of each worker, and each
+// time the manager cancels the work group, to the logger, using the levels
+// in DefaultLogLevels. If logger is nil then slog.Default() is used. To log
+// the value of a panic, the wrapper must itself be wrapped with Recover.
+func Log(m Manager, logger *slog.Logger) Manager {
+	return LogWithLevels(m, logger, DefaultLogLevels)
+}
+
+// LogWithLevels wraps a Manager, m, in the same way as Log,
+// but logs the outcomes of workers using the given levels.
+func LogWithLevels(m Manager, logger *slog.Logger, levels LogLevels) Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &logWrapper{m: m, logger: logger, levels: levels}
+}
+
+func (w *logWrapper) Error() error {
+	return w.m.Error()
+}
+
+func (w *logWrapper) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	attrs := []slog.Attr{slog.Int("index", idx)}
+	if info, ok := workerOf(ctx); ok {
+		attrs = append(attrs, slog.Duration("duration", time.Since(info.started)))
+	}
+
+	level := w.levels.Success
+	if *err != nil {
+		level = w.levels.Error
+		if errors.Is(*err, context.Canceled) {
+			level = w.levels.Canceled
+		}
+		attrs = append(attrs, slog.Any("error", *err))
+
+		var perr *PanicError
+		if errors.As(*err, &perr) {
+			attrs = append(attrs, slog.Any("panic", perr.Value))
+		}
+	}
+
+	w.logger.LogAttrs(ctx, level, "worker completed", attrs...)
+
+	return w.m.Manage(ctx, CancellerFunc(func() {
+		w.logger.LogAttrs(ctx, w.levels.Cancel, "work group canceled", attrs...)
+		c.Cancel()
+	}), idx, err)
+}
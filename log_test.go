@@ -0,0 +1,82 @@
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// logRecords decodes the records written by a JSON handler.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		r := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Log record is not valid JSON: %s", err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLogCancelOnFirstError(t *testing.T) {
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	err := WorkFor(context.Background(), NewLimited(1), Log(CancelOnFirstError(), logger), 3,
+		func(ctx context.Context, index int) error {
+			if index == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err == nil || err.Error() != "worker 1 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	expected := []string{
+		"DEBUG worker completed 0 <nil>",
+		"ERROR worker completed 1 worker 1 failed",
+		"INFO work group canceled 1 worker 1 failed",
+		"DEBUG worker completed 2 context canceled",
+	}
+
+	var records []string
+	for _, r := range logRecords(t, &buf) {
+		if _, ok := r["duration"]; !ok {
+			t.Errorf("Expecting log record to have duration: %v", r)
+		}
+		records = append(records, fmt.Sprintf("%v %v %v %v", r["level"], r["msg"], r["index"], r["error"]))
+	}
+
+	if strings.Join(records, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Expecting log records %q, got %q", expected, records)
+	}
+}
+
+func TestLogPanic(t *testing.T) {
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	WorkFor(context.Background(), nil, Recover(Log(CancelNeverFirstError(), logger)), 1,
+		func(ctx context.Context, index int) error {
+			panic("worker panic")
+		},
+	)
+
+	records := logRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("Expecting one log record, got %d", len(records))
+	}
+
+	if records[0]["level"] != "ERROR" || records[0]["panic"] != "worker panic" {
+		t.Fatalf("Expecting log record of panic, got %v", records[0])
+	}
+}
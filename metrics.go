@@ -0,0 +1,213 @@
+package workgroup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets are the upper bounds, in seconds,
+// of the buckets of the run time histogram of Metrics.
+var DefaultMetricsBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// Metrics keeps counters and gauges for the functions of an executer
+// and the workers of a manager, which are wrapped by the Executer and
+// Manager methods. It implements the expvar.Var interface, so it can be
+// published with expvar.Publish, and the http.Handler interface, which
+// writes the metrics in the Prometheus text format.
+type Metrics struct {
+	mutex     sync.Mutex
+	name      string
+	queued    int64
+	inflight  int64
+	rejected  uint64
+	completed uint64
+	errors    uint64
+	panics    uint64
+	buckets   []float64
+	counts    []uint64
+	sum       float64
+	count     uint64
+}
+
+// NewMetrics initializes new metrics with the given name,
+// which is used as a label in the Prometheus text format.
+func NewMetrics(name string) *Metrics {
+	buckets := append([]float64(nil), DefaultMetricsBuckets...)
+	return &Metrics{
+		name:    name,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// Executer wraps an executer, e, so that the number of functions
+// that are queued, executing and rejected are counted.
+func (m *Metrics) Executer(e Executer) Executer {
+	return Chain(e, WrapFunc(func(_ context.Context, f func(context.Context)) func(context.Context) {
+		m.mutex.Lock()
+		m.queued++
+		m.mutex.Unlock()
+
+		return func(ctx context.Context) {
+			m.mutex.Lock()
+			m.queued--
+			if rejected(ctx) != nil {
+				m.rejected++
+				m.mutex.Unlock()
+				f(ctx)
+				return
+			}
+			m.inflight++
+			m.mutex.Unlock()
+
+			defer func() {
+				m.mutex.Lock()
+				m.inflight--
+				m.mutex.Unlock()
+			}()
+			f(ctx)
+		}
+	}))
+}
+
+type metricsManager struct {
+	metrics *Metrics
+	m       Manager
+}
+
+// Manager wraps a Manager, m, so that the workers that complete, and
+// those that complete with an error, are counted, and the run time of
+// workers, that are not rejected, is added to the histogram. Wrap it
+// with Recover, to also count workers that panic.
+func (m *Metrics) Manager(mgr Manager) Manager {
+	return &metricsManager{metrics: m, m: mgr}
+}
+
+func (w *metricsManager) Error() error {
+	return w.m.Error()
+}
+
+func (w *metricsManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m := w.metrics
+	m.mutex.Lock()
+	m.completed++
+	if *err != nil {
+		m.errors++
+		var perr *PanicError
+		if errors.As(*err, &perr) {
+			m.panics++
+		}
+	}
+	if info, ok := workerOf(ctx); ok && rejected(ctx) == nil {
+		m.observe(time.Since(info.started).Seconds())
+	}
+	m.mutex.Unlock()
+
+	return w.m.Manage(ctx, c, idx, err)
+}
+
+// observe adds the value, v, to the histogram,
+// and must be called with the mutex locked.
+func (m *Metrics) observe(v float64) {
+	for i, b := range m.buckets {
+		if v <= b {
+			m.counts[i]++
+		}
+	}
+	m.sum += v
+	m.count++
+}
+
+type metricsHistogram struct {
+	Buckets map[string]uint64 `json:"buckets"`
+	Sum     float64           `json:"sum"`
+	Count   uint64            `json:"count"`
+}
+
+type metricsSnapshot struct {
+	Queued    int64            `json:"queued"`
+	Inflight  int64            `json:"inflight"`
+	Rejected  uint64           `json:"rejected"`
+	Completed uint64           `json:"completed"`
+	Errors    uint64           `json:"errors"`
+	Panics    uint64           `json:"panics"`
+	Run       metricsHistogram `json:"run_seconds"`
+}
+
+func (m *Metrics) snapshot() metricsSnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := metricsSnapshot{
+		Queued:    m.queued,
+		Inflight:  m.inflight,
+		Rejected:  m.rejected,
+		Completed: m.completed,
+		Errors:    m.errors,
+		Panics:    m.panics,
+		Run: metricsHistogram{
+			Buckets: map[string]uint64{},
+			Sum:     m.sum,
+			Count:   m.count,
+		},
+	}
+	for i, b := range m.buckets {
+		s.Run.Buckets[formatFloat(b)] = m.counts[i]
+	}
+	return s
+}
+
+// String returns the metrics as JSON, and implements the expvar.Var interface.
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.snapshot())
+	return string(b)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the metrics in the Prometheus text format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	s := m.snapshot()
+	label := `name="` + labelEscaper.Replace(m.name) + `"`
+
+	var b strings.Builder
+	metric := func(name, typ, help string, v interface{}) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s{%s} %v\n", name, help, name, typ, name, label, v)
+	}
+	metric("workgroup_queued", "gauge", "Number of functions waiting to execute.", s.Queued)
+	metric("workgroup_inflight", "gauge", "Number of functions executing.", s.Inflight)
+	metric("workgroup_rejected_total", "counter", "Number of functions rejected by the executer.", s.Rejected)
+	metric("workgroup_completed_total", "counter", "Number of workers completed.", s.Completed)
+	metric("workgroup_errors_total", "counter", "Number of workers completed with an error.", s.Errors)
+	metric("workgroup_panics_total", "counter", "Number of workers that panicked.", s.Panics)
+
+	fmt.Fprintf(&b, "# HELP workgroup_run_seconds Run time of workers.\n# TYPE workgroup_run_seconds histogram\n")
+	for _, bound := range m.buckets {
+		le := formatFloat(bound)
+		fmt.Fprintf(&b, "workgroup_run_seconds_bucket{%s,le=\"%s\"} %d\n", label, le, s.Run.Buckets[le])
+	}
+	fmt.Fprintf(&b, "workgroup_run_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, s.Run.Count)
+	fmt.Fprintf(&b, "workgroup_run_seconds_sum{%s} %s\n", label, formatFloat(s.Run.Sum))
+	fmt.Fprintf(&b, "workgroup_run_seconds_count{%s} %d\n", label, s.Run.Count)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,130 @@
+package workgroup
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	_ expvar.Var   = (*Metrics)(nil)
+	_ http.Handler = (*Metrics)(nil)
+)
+
+func TestMetricsCounters(t *testing.T) {
+
+	m := NewMetrics("test")
+
+	err := WorkFor(context.Background(), m.Executer(NewLimited(2)), Recover(m.Manager(CancelNeverFirstError())), 10,
+		func(ctx context.Context, index int) error {
+			if index == 9 {
+				panic("worker panic")
+			}
+			if index%3 == 0 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err == nil {
+		t.Fatalf("Work group error is nil")
+	}
+
+	s := m.snapshot()
+	if s.Queued != 0 || s.Inflight != 0 {
+		t.Errorf("Expecting no functions queued or in-flight, got %d and %d", s.Queued, s.Inflight)
+	}
+	if s.Completed != 10 || s.Errors != 4 || s.Panics != 1 || s.Rejected != 0 {
+		t.Errorf("Expecting 10 completed, 4 errors, 1 panic and 0 rejected: %+v", s)
+	}
+	if s.Run.Count != 10 || s.Run.Buckets["10"] != 10 {
+		t.Errorf("Expecting 10 run times in histogram: %+v", s.Run)
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(m.String()), &v); err != nil {
+		t.Fatalf("Metrics string is not valid JSON: %s", err)
+	}
+	if v["completed"] != float64(10) {
+		t.Errorf("Expecting 10 completed in JSON, got %v", v["completed"])
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE workgroup_completed_total counter",
+		`workgroup_completed_total{name="test"} 10`,
+		`workgroup_errors_total{name="test"} 4`,
+		`workgroup_panics_total{name="test"} 1`,
+		`workgroup_inflight{name="test"} 0`,
+		`workgroup_run_seconds_bucket{name="test",le="+Inf"} 10`,
+		`workgroup_run_seconds_count{name="test"} 10`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expecting metrics to contain %q:\n%s", line, body)
+		}
+	}
+}
+
+func TestMetricsGauges(t *testing.T) {
+
+	m := NewMetrics("test")
+
+	release := make(chan struct{})
+
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(context.Background(), m.Executer(NewLimited(2)), CancelOnFirstError(), 4,
+			func(ctx context.Context, index int) error {
+				<-release
+				return nil
+			},
+		)
+	}()
+
+	// Wait for the workers to start, and the next to be queued
+	for {
+		s := m.snapshot()
+		if s.Inflight == 2 && s.Queued == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if s := m.snapshot(); s.Inflight != 0 || s.Queued != 0 {
+		t.Fatalf("Expecting no functions queued or in-flight, got %d and %d", s.Queued, s.Inflight)
+	}
+}
+
+func TestMetricsRejected(t *testing.T) {
+
+	m := NewMetrics("test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	WorkFor(ctx, m.Executer(NewLimited(1)), m.Manager(CancelNeverFirstError()), 10,
+		func(ctx context.Context, index int) error {
+			return nil
+		},
+	)
+
+	s := m.snapshot()
+	if s.Rejected != 10 || s.Completed != 10 || s.Errors != 10 || s.Run.Count != 0 {
+		t.Fatalf("Expecting 10 rejected and completed with errors and no run times: %+v", s)
+	}
+}
@@ -0,0 +1,122 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+// ExecuterMiddleware is a function that wraps an executer to add
+// behavior to it. Middleware should forward the Wait method to the
+// wrapped executer if it implements the Waiter interface, as is done
+// by the middleware returned by WrapFunc.
+type ExecuterMiddleware func(Executer) Executer
+
+// Chain returns the executer, base, wrapped by each of the middleware,
+// mw, in order, so that the last middleware is the outermost executer.
+// Since each middleware wraps the function that it submits to the next
+// executer, the function of the first middleware is the outermost when
+// the function is executed. If base is nil then the executer provided
+// by DefaultExecuter is used.
+func Chain(base Executer, mw ...ExecuterMiddleware) Executer {
+	if base == nil {
+		base = DefaultExecuter()
+	}
+	for _, m := range mw {
+		base = m(base)
+	}
+	return base
+}
+
+type wrapped struct {
+	e    Executer
+	wrap func(ctx context.Context, f func(context.Context)) func(context.Context)
+}
+
+// WrapFunc returns a middleware that calls the function, wrap, each time
+// a function, f, is submitted, and then submits the function that it returns
+// in place of f. The returned function must call f with the context that it
+// is given, or with a context derived from it. Note that if the function is
+// rejected, then it is called with a context for which Rejected is not nil.
+func WrapFunc(wrap func(ctx context.Context, f func(context.Context)) func(context.Context)) ExecuterMiddleware {
+	return func(e Executer) Executer {
+		return &wrapped{e: e, wrap: wrap}
+	}
+}
+
+func (w *wrapped) Execute(ctx context.Context, f func(context.Context)) {
+	w.e.Execute(ctx, w.wrap(ctx, f))
+}
+
+func (w *wrapped) Wait(ctx context.Context, done <-chan struct{}) {
+	if waiter, ok := w.e.(Waiter); ok {
+		waiter.Wait(ctx, done)
+	}
+}
+
+// Rejected returns the error given to Reject, if the context is
+// that of a function that has been rejected, otherwise nil.
+func Rejected(ctx context.Context) error {
+	return rejected(ctx)
+}
+
+// MapContext returns a middleware that executes each function
+// with the context returned by calling mapper with its context.
+func MapContext(mapper func(ctx context.Context) context.Context) ExecuterMiddleware {
+	return WrapFunc(func(_ context.Context, f func(context.Context)) func(context.Context) {
+		return func(ctx context.Context) {
+			f(mapper(ctx))
+		}
+	})
+}
+
+// Timing returns a middleware that calls observe after each function
+// completes, with the time it waited to start after being submitted
+// and the time it took to run. Rejected functions are not observed.
+// Note that observe may be called after the work group has completed.
+func Timing(observe func(ctx context.Context, wait, run time.Duration)) ExecuterMiddleware {
+	return timing(realClock{}, observe)
+}
+
+func timing(c clock, observe func(ctx context.Context, wait, run time.Duration)) ExecuterMiddleware {
+	return WrapFunc(func(_ context.Context, f func(context.Context)) func(context.Context) {
+		submitted := c.Now()
+		return func(ctx context.Context) {
+			if rejected(ctx) != nil {
+				f(ctx)
+				return
+			}
+			started := c.Now()
+			defer func() {
+				observe(ctx, started.Sub(submitted), c.Now().Sub(started))
+			}()
+			f(ctx)
+		}
+	})
+}
+
+type recoverPanicsKey struct{}
+
+// RecoverPanics returns a middleware that recovers a panic of each
+// function, so that it does not crash the program, and calls handler
+// with the context and the value returned from recover. The panic of
+// a worker is recovered before its error is given to the manager, and
+// the error is replaced with a PanicError, as is done by Recover.
+func RecoverPanics(handler func(ctx context.Context, v interface{})) ExecuterMiddleware {
+	return WrapFunc(func(_ context.Context, f func(context.Context)) func(context.Context) {
+		return func(ctx context.Context) {
+			defer func() {
+				if v := recover(); v != nil && handler != nil {
+					handler(ctx, v)
+				}
+			}()
+			f(context.WithValue(ctx, recoverPanicsKey{}, handler))
+		}
+	})
+}
+
+// recoverPanicsOf returns the handler of the context, and false
+// if the context is not of a function executed by RecoverPanics.
+func recoverPanicsOf(ctx context.Context) (func(ctx context.Context, v interface{}), bool) {
+	handler, ok := ctx.Value(recoverPanicsKey{}).(func(ctx context.Context, v interface{}))
+	return handler, ok
+}
@@ -0,0 +1,206 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChainOrder(t *testing.T) {
+
+	var mutex sync.Mutex
+	var order []string
+
+	record := func(name string) ExecuterMiddleware {
+		return WrapFunc(func(_ context.Context, f func(context.Context)) func(context.Context) {
+			return func(ctx context.Context) {
+				mutex.Lock()
+				order = append(order, name)
+				mutex.Unlock()
+				f(ctx)
+			}
+		})
+	}
+
+	e := Chain(NewLimited(2), record("a"), record("b"))
+
+	err := WorkFor(context.Background(), e, CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			mutex.Lock()
+			order = append(order, "w")
+			mutex.Unlock()
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if len(order) != 30 {
+		t.Fatalf("Expecting 30 calls, got %d", len(order))
+	}
+
+	counts := map[string]int{}
+	for _, name := range order {
+		counts[name]++
+		if counts["a"] < counts["b"] || counts["b"] < counts["w"] {
+			t.Fatalf("Middleware is not called in order: %v", order)
+		}
+	}
+}
+
+func TestChainWaiter(t *testing.T) {
+
+	var order []string
+
+	e := Chain(NewSequential(), MapContext(func(ctx context.Context) context.Context {
+		return ctx
+	}))
+
+	record := func(name string) Worker {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	// The nested group would deadlock if the
+	// middleware did not forward the Wait method.
+	err := Work(context.Background(), e, CancelOnFirstError(),
+		Group(e, CancelOnFirstError(), record("a1"), record("a2")),
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	expected := []string{"a1", "a2"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Fatalf("Expecting order %v, got %v", expected, order)
+	}
+}
+
+func TestMapContext(t *testing.T) {
+
+	type key struct{}
+
+	e := Chain(nil, MapContext(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, key{}, "mapped")
+	}))
+
+	err := WorkFor(context.Background(), e, CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			if v := ctx.Value(key{}); v != "mapped" {
+				return fmt.Errorf("worker %d context value is %v", index, v)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
+
+func TestTiming(t *testing.T) {
+
+	var mutex sync.Mutex
+	var runs []time.Duration
+
+	c := newFakeClock()
+
+	observe := func(ctx context.Context, wait, run time.Duration) {
+		if wait != 0 {
+			t.Errorf("Expecting no wait for inline executer, got %s", wait)
+		}
+		mutex.Lock()
+		runs = append(runs, run)
+		mutex.Unlock()
+	}
+
+	err := WorkFor(context.Background(), Chain(NewInline(), timing(c, observe)), CancelOnFirstError(), 3,
+		func(ctx context.Context, index int) error {
+			c.Advance(time.Duration(index+1) * time.Millisecond)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+	if fmt.Sprint(runs) != fmt.Sprint(expected) {
+		t.Fatalf("Expecting run times %v, got %v", expected, runs)
+	}
+
+	rejectErr := fmt.Errorf("rejected")
+
+	e := Chain(ExecuterFunc(func(ctx context.Context, f func(context.Context)) {
+		Reject(ctx, f, rejectErr)
+	}), timing(c, func(ctx context.Context, wait, run time.Duration) {
+		t.Errorf("Rejected worker must not be observed")
+	}))
+
+	err = WorkFor(context.Background(), e, CancelOnFirstError(), 3,
+		func(ctx context.Context, index int) error {
+			return nil
+		},
+	)
+	if err != rejectErr {
+		t.Fatalf("Work group error is not rejected error: %v", err)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+
+	var mutex sync.Mutex
+	var values []interface{}
+
+	e := Chain(NewUnlimited(), RecoverPanics(func(ctx context.Context, v interface{}) {
+		mutex.Lock()
+		values = append(values, v)
+		mutex.Unlock()
+	}))
+
+	err := WorkFor(context.Background(), e, CancelOnFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			if index == 3 {
+				panic("worker panic")
+			}
+			return nil
+		},
+	)
+
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "worker panic" {
+		t.Fatalf("Work group error is not panic error: %v", err)
+	}
+
+	if fmt.Sprint(values) != "[worker panic]" {
+		t.Fatalf("Expecting the panic to be handled, got %v", values)
+	}
+
+	// The panic is not the first success
+	err = WorkFor(context.Background(), e, CancelOnFirstSuccess(), 1,
+		func(ctx context.Context, index int) error {
+			panic("worker panic")
+		},
+	)
+	if !errors.As(err, &perr) {
+		t.Fatalf("Work group error is not panic error: %v", err)
+	}
+
+	// A nested work group does not inherit the handler
+	err = Work(context.Background(), e, CancelOnFirstError(),
+		func(ctx context.Context) error {
+			if _, ok := recoverPanicsOf(ctx); ok {
+				return fmt.Errorf("worker context has the handler")
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
@@ -0,0 +1,85 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+// Observer is notified of the events in the lifecycle of a work group.
+// The methods may be called simultaneously from different goroutines.
+type Observer interface {
+	// OnSubmit is called before the worker with
+	// the given index is given to the executer.
+	OnSubmit(ctx context.Context, idx int)
+
+	// OnStart is called when the worker with the given
+	// index starts, with the time it waited to start.
+	// It is not called for workers that are rejected.
+	OnStart(ctx context.Context, idx int, wait time.Duration)
+
+	// OnFinish is called when the worker with the given index
+	// completes, with the time that it ran and its error, after
+	// it has been given to the manager. It is called for workers
+	// that are rejected with the error given to Reject.
+	OnFinish(ctx context.Context, idx int, run time.Duration, err error)
+
+	// OnCancel is called once, when the work group is first canceled
+	// before all workers complete. If the manager cancels the work group,
+	// then it is called with the index and error of the worker whose
+	// completion caused the cancellation, and if the parent context is
+	// done, then it is called with an index of -1 and the context error.
+	OnCancel(ctx context.Context, idx int, err error)
+
+	// OnGroupDone is called when all workers have completed,
+	// with the time since the work group started and its error.
+	OnGroupDone(ctx context.Context, elapsed time.Duration, err error)
+}
+
+type observerKey struct{}
+
+type observers []Observer
+
+// WithObserver returns a copy of the context with observer, o, which
+// is notified of the events of a work group that uses the context.
+// Observers are not notified of the events of nested work groups,
+// unless they are also given to the contexts of those work groups.
+func WithObserver(ctx context.Context, o Observer) context.Context {
+	obs := observersOf(ctx)
+	return context.WithValue(ctx, observerKey{}, append(obs[:len(obs):len(obs)], o))
+}
+
+// observersOf returns the observers of the context, or nil if there are none.
+func observersOf(ctx context.Context) observers {
+	obs, _ := ctx.Value(observerKey{}).(observers)
+	return obs
+}
+
+func (obs observers) OnSubmit(ctx context.Context, idx int) {
+	for _, o := range obs {
+		o.OnSubmit(ctx, idx)
+	}
+}
+
+func (obs observers) OnStart(ctx context.Context, idx int, wait time.Duration) {
+	for _, o := range obs {
+		o.OnStart(ctx, idx, wait)
+	}
+}
+
+func (obs observers) OnFinish(ctx context.Context, idx int, run time.Duration, err error) {
+	for _, o := range obs {
+		o.OnFinish(ctx, idx, run, err)
+	}
+}
+
+func (obs observers) OnCancel(ctx context.Context, idx int, err error) {
+	for _, o := range obs {
+		o.OnCancel(ctx, idx, err)
+	}
+}
+
+func (obs observers) OnGroupDone(ctx context.Context, elapsed time.Duration, err error) {
+	for _, o := range obs {
+		o.OnGroupDone(ctx, elapsed, err)
+	}
+}
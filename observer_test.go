@@ -0,0 +1,198 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordObserver struct {
+	mutex  sync.Mutex
+	events []string
+	errs   map[int]error
+}
+
+func (o *recordObserver) record(format string, args ...interface{}) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+// cancels returns the cancel events that have been recorded.
+func (o *recordObserver) cancels() []string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	var cancels []string
+	for _, e := range o.events {
+		if strings.HasPrefix(e, "cancel") {
+			cancels = append(cancels, e)
+		}
+	}
+	return cancels
+}
+
+func (o *recordObserver) OnSubmit(ctx context.Context, idx int) {
+	o.record("submit %d", idx)
+}
+
+func (o *recordObserver) OnStart(ctx context.Context, idx int, wait time.Duration) {
+	o.record("start %d", idx)
+}
+
+func (o *recordObserver) OnFinish(ctx context.Context, idx int, run time.Duration, err error) {
+	o.record("finish %d", idx)
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.errs == nil {
+		o.errs = map[int]error{}
+	}
+	o.errs[idx] = err
+}
+
+func (o *recordObserver) OnCancel(ctx context.Context, idx int, err error) {
+	o.record("cancel %d %v", idx, err)
+}
+
+func (o *recordObserver) OnGroupDone(ctx context.Context, elapsed time.Duration, err error) {
+	o.record("done %v", err)
+}
+
+func TestObserverCancelOnFirstError(t *testing.T) {
+
+	o := &recordObserver{}
+
+	ctx := WithObserver(context.Background(), o)
+
+	err := WorkFor(ctx, NewLimited(1), CancelOnFirstError(), 4,
+		func(ctx context.Context, index int) error {
+			if index == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err == nil || err.Error() != "worker 1 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for _, e := range o.events {
+		counts[e]++
+	}
+
+	expected := map[string]int{
+		"submit 0": 1, "submit 1": 1, "submit 2": 1, "submit 3": 1,
+		"start 0": 1, "start 1": 1,
+		"finish 0": 1, "finish 1": 1, "finish 2": 1, "finish 3": 1,
+		"cancel 1 worker 1 failed": 1,
+		"done worker 1 failed":     1,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(expected) {
+		t.Fatalf("Expecting events %v, got %v", expected, o.events)
+	}
+
+	if o.events[len(o.events)-1] != "done worker 1 failed" {
+		t.Fatalf("Expecting group done to be the last event: %v", o.events)
+	}
+
+	for _, i := range []int{2, 3} {
+		if o.errs[i] != context.Canceled {
+			t.Errorf("Expecting worker %d to finish with canceled error: %v", i, o.errs[i])
+		}
+	}
+}
+
+func TestObserverPanic(t *testing.T) {
+
+	o := &recordObserver{}
+
+	ctx := WithObserver(context.Background(), o)
+
+	err := WorkFor(ctx, nil, Recover(CancelNeverFirstError()), 2,
+		func(ctx context.Context, index int) error {
+			if index == 1 {
+				panic("worker panic")
+			}
+			return nil
+		},
+	)
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Work group error is not panic error: %v", err)
+	}
+
+	if !errors.As(o.errs[1], &perr) {
+		t.Fatalf("Expecting worker to finish with panic error: %v", o.errs[1])
+	}
+}
+
+func TestObserverNested(t *testing.T) {
+
+	outer := &recordObserver{}
+	inner := &recordObserver{}
+
+	ctx := WithObserver(context.Background(), outer)
+
+	err := Work(ctx, nil, CancelOnFirstError(),
+		func(ctx context.Context) error {
+			return WorkFor(ctx, nil, CancelOnFirstError(), 2,
+				func(ctx context.Context, index int) error {
+					return nil
+				},
+			)
+		},
+		func(ctx context.Context) error {
+			return WorkFor(WithObserver(ctx, inner), nil, CancelOnFirstError(), 2,
+				func(ctx context.Context, index int) error {
+					return nil
+				},
+			)
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if len(outer.events) != 7 {
+		t.Fatalf("Expecting 7 events for outer group, got %v", outer.events)
+	}
+
+	if len(inner.events) != 7 {
+		t.Fatalf("Expecting 7 events for inner group, got %v", inner.events)
+	}
+}
+
+func TestObserverParentCanceled(t *testing.T) {
+
+	o := &recordObserver{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := WorkFor(WithObserver(ctx, o), nil, CancelNeverFirstError(), 2,
+		func(ctx context.Context, index int) error {
+			if index == 0 {
+				cancel()
+			}
+			<-ctx.Done()
+
+			// The cancel event is notified before the workers complete
+			for i := 0; i < 1000 && len(o.cancels()) == 0; i++ {
+				time.Sleep(time.Millisecond)
+			}
+			return ctx.Err()
+		},
+	)
+	if err != context.Canceled {
+		t.Fatalf("Work group error is not canceled: %v", err)
+	}
+
+	if cancels := o.cancels(); fmt.Sprint(cancels) != "[cancel -1 context canceled]" {
+		t.Fatalf("Expecting one cancel event for the parent context, got %v", cancels)
+	}
+}
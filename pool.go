@@ -0,0 +1,336 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrExecuterClosed is given to the manager in place of the
+// error from a worker that is submitted to a closed executer.
+var ErrExecuterClosed = errors.New("executer closed")
+
+// ErrQueueFull is given to the manager in place of the error
+// from a worker that is rejected because a queue is full.
+var ErrQueueFull = errors.New("queue full")
+
+type task struct {
+	ctx context.Context
+	f   func(context.Context)
+}
+
+// Pool is an executer that executes functions on a fixed
+// number of goroutines, which may be changed with Resize.
+type Pool struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	wg       sync.WaitGroup
+	queue    []task
+	size     int
+	running  int
+	idle     int
+	waiting  int
+	capacity int
+	policy   RejectPolicy
+	closed   bool
+	quit     chan struct{}
+	space    chan struct{}
+}
+
+// NewPool initializes a new pool executer that will execute
+// functions on fixed number of goroutines. If n <= 0 then
+// the values in DefaultLimit is used. Note that the provided
+// context must be cancelled, or the pool closed, to ensure
+// that the pool releases all resources.
+func NewPool(ctx context.Context, n int) *Pool {
+	return NewQueuedPool(ctx, n, 0, BlockPolicy)
+}
+
+// NewQueuedPool initializes a new pool executer, like NewPool,
+// but with a queue that holds up to capacity functions waiting
+// for a goroutine. When the queue is full, the policy decides
+// what happens to the submitted function. If policy is nil
+// then BlockPolicy is used.
+func NewQueuedPool(ctx context.Context, n int, capacity int, policy RejectPolicy) *Pool {
+	if capacity < 0 {
+		capacity = 0
+	}
+	if policy == nil {
+		policy = BlockPolicy
+	}
+
+	p := &Pool{
+		capacity: capacity,
+		policy:   policy,
+		quit:     make(chan struct{}),
+		space:    make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mutex)
+
+	if ctx != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				p.Close()
+			case <-p.quit:
+			}
+		}()
+	}
+
+	p.Resize(n)
+	return p
+}
+
+// Size returns the number of goroutines of the pool.
+func (p *Pool) Size() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.size
+}
+
+// Resize changes the number of goroutines of the pool to n.
+// If n <= 0 then the value in DefaultLimit is used. When the
+// pool shrinks, goroutines exit after completing the function
+// that they are executing, and functions that have been
+// submitted are executed by the remaining goroutines.
+// Resizing a closed pool has no effect.
+func (p *Pool) Resize(n int) {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.size = n
+	for p.running < p.size {
+		p.running++
+		p.wg.Add(1)
+		go p.work()
+	}
+	if p.running > p.size {
+		p.cond.Broadcast()
+	}
+}
+
+// Execute arranges for the function, f, to be executed on a
+// goroutine of the pool, if one is available, or queues it.
+// If the queue is full, then the reject policy is applied.
+// If the pool is closed, then the function is rejected with
+// ErrExecuterClosed, and if the context is done, when it is
+// submitted or taken from the queue, then the function is
+// rejected with the context error.
+func (p *Pool) Execute(ctx context.Context, f func(context.Context)) {
+	if !p.enqueue(ctx, f, false) {
+		p.policy(ctx, f, p)
+	}
+}
+
+// accept returns true if there is space in the queue for a
+// function, either for an idle goroutine or in the queue
+// itself, and must be called with the mutex locked.
+func (p *Pool) accept() bool {
+	return len(p.queue) < p.capacity+p.idle
+}
+
+// enqueue queues the function, f, if there is space, or rejects
+// it if the pool is closed or the context is done. If block is
+// true then it waits for space, otherwise it returns false if
+// the function has been neither queued nor rejected.
+func (p *Pool) enqueue(ctx context.Context, f func(context.Context), block bool) bool {
+	p.mutex.Lock()
+	for {
+		if p.closed {
+			p.mutex.Unlock()
+			Reject(ctx, f, ErrExecuterClosed)
+			return true
+		}
+
+		if err := ctx.Err(); err != nil {
+			p.mutex.Unlock()
+			Reject(ctx, f, err)
+			return true
+		}
+
+		if p.accept() {
+			p.queue = append(p.queue, task{ctx: ctx, f: f})
+			p.cond.Signal()
+			p.mutex.Unlock()
+			return true
+		}
+
+		if !block {
+			p.mutex.Unlock()
+			return false
+		}
+
+		p.waiting++
+		space := p.space
+		p.mutex.Unlock()
+
+		select {
+		case <-space:
+		case <-p.quit:
+		case <-ctx.Done():
+		}
+
+		p.mutex.Lock()
+		p.waiting--
+	}
+}
+
+// Close closes the pool so that no further functions will be
+// executed. Functions that have been submitted, but not yet
+// started, are rejected with ErrExecuterClosed. Close does
+// not wait for functions that are executing to complete.
+func (p *Pool) Close() {
+	p.mutex.Lock()
+	p.shutdown()
+	queue := p.queue
+	p.queue = nil
+	p.mutex.Unlock()
+
+	for _, t := range queue {
+		Reject(t.ctx, t.f, ErrExecuterClosed)
+	}
+}
+
+// Drain closes the pool to new functions and waits for all the
+// functions that have been submitted to complete. If the context
+// is done before that happens, then the context error is returned.
+// Drain must not be called from a function executing on the pool.
+func (p *Pool) Drain(ctx context.Context) error {
+	p.mutex.Lock()
+	p.shutdown()
+	p.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown marks the pool closed, and must be called with the mutex locked.
+func (p *Pool) shutdown() {
+	if !p.closed {
+		p.closed = true
+		close(p.quit)
+		p.cond.Broadcast()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		p.mutex.Lock()
+		t, ok := p.next()
+		p.mutex.Unlock()
+
+		if !ok {
+			return
+		}
+		if err := t.ctx.Err(); err != nil {
+			Reject(t.ctx, t.f, err)
+			continue
+		}
+		t.f(t.ctx)
+	}
+}
+
+// next waits for a task to be queued and must be called with the
+// mutex locked. If the pool is closed and the queue is empty, or
+// the pool has shrunk, then it returns false to indicate that the
+// goroutine should exit.
+func (p *Pool) next() (task, bool) {
+	for len(p.queue) == 0 && !p.closed && p.running <= p.size {
+		p.idle++
+		p.notify()
+		p.cond.Wait()
+		p.idle--
+	}
+
+	if len(p.queue) == 0 || p.running > p.size {
+		p.running--
+		if len(p.queue) > 0 {
+			// Ensure that another goroutine takes the
+			// task that may have been queued for this one.
+			p.cond.Signal()
+		}
+		return task{}, false
+	}
+
+	t := p.queue[0]
+	p.queue[0] = task{}
+	p.queue = p.queue[1:]
+	p.notify()
+	return t, true
+}
+
+// notify wakes any submitters that are waiting for space
+// in the queue, and must be called with the mutex locked.
+func (p *Pool) notify() {
+	if p.waiting > 0 {
+		close(p.space)
+		p.space = make(chan struct{})
+	}
+}
+
+// RejectPolicy is called by a pool when the function, f, is
+// submitted and its queue is full. The policy must arrange
+// for the function to be executed or rejected (see Reject).
+type RejectPolicy func(ctx context.Context, f func(context.Context), p *Pool)
+
+// BlockPolicy waits for space in the queue of the pool,
+// this is the policy used by the pool returned from NewPool.
+func BlockPolicy(ctx context.Context, f func(context.Context), p *Pool) {
+	p.enqueue(ctx, f, true)
+}
+
+// AbortPolicy rejects the function with ErrQueueFull.
+func AbortPolicy(ctx context.Context, f func(context.Context), p *Pool) {
+	Reject(ctx, f, ErrQueueFull)
+}
+
+// CallerRunsPolicy executes the function on the goroutine
+// that submitted it, which is usually the work group goroutine.
+func CallerRunsPolicy(ctx context.Context, f func(context.Context), p *Pool) {
+	f(ctx)
+}
+
+// DropOldestPolicy rejects the oldest function in the queue of
+// the pool with ErrQueueFull, and then queues the function. If
+// the queue is empty, the function is rejected instead.
+func DropOldestPolicy(ctx context.Context, f func(context.Context), p *Pool) {
+	p.mutex.Lock()
+	if p.closed || ctx.Err() != nil || p.accept() || len(p.queue) == 0 {
+		p.mutex.Unlock()
+		if !p.enqueue(ctx, f, false) {
+			Reject(ctx, f, ErrQueueFull)
+		}
+		return
+	}
+
+	t := p.queue[0]
+	p.queue[0] = task{}
+	p.queue = append(p.queue[1:], task{ctx: ctx, f: f})
+	p.mutex.Unlock()
+
+	Reject(t.ctx, t.f, ErrQueueFull)
+}
@@ -0,0 +1,365 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolExecuteAfterCancel(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewPool(ctx, 8)
+
+	cancel()
+
+	// Wait for the pool to close
+	for {
+		p.mutex.Lock()
+		closed := p.closed
+		p.mutex.Unlock()
+		if closed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	err := WorkFor(context.Background(), p, CancelNeverFirstError(), 100,
+		func(ctx context.Context, index int) error {
+			t.Errorf("Worker %d must not start", index)
+			return nil
+		},
+	)
+
+	if err != ErrExecuterClosed {
+		t.Fatalf("Work group error is not closed error: %v", err)
+	}
+}
+
+func TestPoolCloseDuringWork(t *testing.T) {
+
+	var started int64
+
+	p := NewPool(nil, 8)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.Close()
+	}()
+
+	err := WorkFor(context.Background(), p, CancelNeverFirstError(), 10000,
+		func(ctx context.Context, index int) error {
+			atomic.AddInt64(&started, 1)
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+	)
+
+	if err != ErrExecuterClosed {
+		t.Fatalf("Work group error is not closed error: %v", err)
+	}
+
+	if started == 0 || started == 10000 {
+		t.Fatalf("Expecting some workers to start, got %d", started)
+	}
+}
+
+func TestPoolDrain(t *testing.T) {
+
+	var started, completed int64
+
+	p := NewPool(nil, 8)
+
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(context.Background(), p, CancelNeverFirstError(), 8,
+			func(ctx context.Context, index int) error {
+				atomic.AddInt64(&started, 1)
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt64(&completed, 1)
+				return nil
+			},
+		)
+	}()
+
+	// Wait for all the workers to start
+	for atomic.LoadInt64(&started) < 8 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("Pool drain error is not nil: %s", err)
+	}
+
+	if completed != 8 {
+		t.Fatalf("Expecting 8 workers to complete, got %d", completed)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
+
+func TestPoolDrainTimeout(t *testing.T) {
+
+	p := NewPool(nil, 1)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	p.Execute(context.Background(), func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := p.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Pool drain error is not deadline exceeded: %v", err)
+	}
+}
+
+func TestPoolResize(t *testing.T) {
+
+	counts := make([]int, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 8)
+	if p.Size() != 8 {
+		t.Fatalf("Expecting pool size of 8, got %d", p.Size())
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		p.Resize(2)
+	}()
+
+	err := WorkFor(ctx, p, CancelNeverFirstError(), len(counts),
+		func(ctx context.Context, index int) error {
+			time.Sleep(time.Millisecond)
+			counts[index]++
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	for _, c := range counts {
+		if c != 1 {
+			t.Errorf("Worker %d has not completed", c)
+		}
+	}
+
+	if p.Size() != 2 {
+		t.Fatalf("Expecting pool size of 2, got %d", p.Size())
+	}
+
+	tokens := make(chan struct{}, 2)
+
+	WorkFor(ctx, p, CancelNeverFirstError(), 100,
+		func(ctx context.Context, index int) error {
+			select {
+			case tokens <- struct{}{}:
+				break
+			default:
+				t.Errorf("Worker %d must wait to send token", index)
+			}
+
+			time.Sleep(time.Millisecond)
+
+			<-tokens
+			return nil
+		},
+	)
+
+	p.Resize(4)
+
+	var running, peak int64
+
+	WorkFor(ctx, p, CancelNeverFirstError(), 100,
+		func(ctx context.Context, index int) error {
+			n := atomic.AddInt64(&running, 1)
+			defer atomic.AddInt64(&running, -1)
+			for {
+				m := atomic.LoadInt64(&peak)
+				if n <= m || atomic.CompareAndSwapInt64(&peak, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+	)
+
+	if peak != 4 {
+		t.Fatalf("Expecting a maximum of 4 workers running, got %d", peak)
+	}
+}
+
+// blockExecuter submits a function to the executer, such as a pool,
+// that blocks until the returned function is called to release it.
+func blockExecuter(e Executer) func() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Execute(context.Background(), func(ctx context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+	return func() { close(release) }
+}
+
+func TestQueuedPoolAbortPolicy(t *testing.T) {
+
+	var started [4]int64
+
+	p := NewQueuedPool(nil, 1, 2, AbortPolicy)
+	defer p.Close()
+
+	release := blockExecuter(p)
+
+	m := &AccumulateManager{
+		manager: CancelOnFirstError(),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(context.Background(), p, m, 4,
+			func(ctx context.Context, index int) error {
+				atomic.AddInt64(&started[index], 1)
+				return nil
+			},
+		)
+	}()
+
+	// Wait for the rejected workers to be managed
+	for {
+		m.mutex.Lock()
+		n := len(m.Errors)
+		m.mutex.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	release()
+
+	if err := <-done; err != ErrQueueFull {
+		t.Fatalf("Work group error is not queue full error: %v", err)
+	}
+
+	for i, e := range m.Errors[1:] {
+		if e != context.Canceled {
+			t.Fatalf("Expecting accumulated error (%d) to be canceled: %v", i+1, e)
+		}
+	}
+
+	// The queued workers are rejected, since the
+	// work group was canceled while they waited.
+	for i, s := range started {
+		if s != 0 {
+			t.Errorf("Worker %d must not start", i)
+		}
+	}
+}
+
+func TestQueuedPoolCallerRunsPolicy(t *testing.T) {
+
+	var started [4]int64
+
+	p := NewQueuedPool(nil, 1, 2, CallerRunsPolicy)
+	defer p.Close()
+
+	release := blockExecuter(p)
+
+	ran := make(chan struct{})
+
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(context.Background(), p, CancelNeverFirstError(), 4,
+			func(ctx context.Context, index int) error {
+				atomic.AddInt64(&started[index], 1)
+				if index == 3 {
+					close(ran)
+				}
+				return nil
+			},
+		)
+	}()
+
+	// Workers that do not fit in the queue must
+	// run while the pool goroutine is blocked
+	<-ran
+
+	if atomic.LoadInt64(&started[0]) != 0 || atomic.LoadInt64(&started[1]) != 0 {
+		t.Errorf("Queued workers must not start while the pool is blocked")
+	}
+
+	release()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	for i, s := range started {
+		if s != 1 {
+			t.Errorf("Worker %d has not completed", i)
+		}
+	}
+}
+
+func TestQueuedPoolDropOldestPolicy(t *testing.T) {
+
+	var started [4]int64
+
+	p := NewQueuedPool(nil, 1, 2, DropOldestPolicy)
+	defer p.Close()
+
+	release := blockExecuter(p)
+
+	m := &AccumulateManager{
+		manager: CancelNeverFirstError(),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(context.Background(), p, m, 4,
+			func(ctx context.Context, index int) error {
+				atomic.AddInt64(&started[index], 1)
+				return nil
+			},
+		)
+	}()
+
+	// Wait for the dropped workers to be managed
+	for {
+		m.mutex.Lock()
+		n := len(m.Errors)
+		m.mutex.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	release()
+
+	if err := <-done; err != ErrQueueFull {
+		t.Fatalf("Work group error is not queue full error: %v", err)
+	}
+
+	for i, s := range started {
+		if i < 2 && s != 0 {
+			t.Errorf("Worker %d must not start", i)
+		}
+		if i >= 2 && s != 1 {
+			t.Errorf("Worker %d has not completed", i)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package workgroup
+
+import (
+	"container/heap"
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of the context with priority, p,
+// which is used by the priority executer to order functions.
+// Functions with a higher priority are executed first.
+func WithPriority(ctx context.Context, p int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// Priority returns the priority of the context, or zero
+// if a priority has not been set with WithPriority.
+func Priority(ctx context.Context) int {
+	p, _ := ctx.Value(priorityKey{}).(int)
+	return p
+}
+
+type priorityTask struct {
+	task
+	priority int
+	seq      uint64
+	queued   time.Duration
+}
+
+// priorityQueue implements heap.Interface to order tasks by priority.
+// When aging is enabled, the priority of a task is increased by one
+// for every aging period that it has been waiting. Since all tasks age
+// at the same rate, this ordering does not change while they wait.
+type priorityQueue struct {
+	tasks []priorityTask
+	aging time.Duration
+}
+
+func (q *priorityQueue) Len() int {
+	return len(q.tasks)
+}
+
+func (q *priorityQueue) Less(i, j int) bool {
+	a, b := &q.tasks[i], &q.tasks[j]
+	if q.aging > 0 {
+		ka := int64(a.priority)*int64(q.aging) - int64(a.queued)
+		kb := int64(b.priority)*int64(q.aging) - int64(b.queued)
+		if ka != kb {
+			return ka > kb
+		}
+	} else if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (q *priorityQueue) Swap(i, j int) {
+	q.tasks[i], q.tasks[j] = q.tasks[j], q.tasks[i]
+}
+
+func (q *priorityQueue) Push(x interface{}) {
+	q.tasks = append(q.tasks, x.(priorityTask))
+}
+
+func (q *priorityQueue) Pop() interface{} {
+	n := len(q.tasks) - 1
+	t := q.tasks[n]
+	q.tasks[n] = priorityTask{}
+	q.tasks = q.tasks[:n]
+	return t
+}
+
+type prioritized struct {
+	mutex   sync.Mutex
+	clock   clock
+	start   time.Time
+	n       int
+	running int
+	seq     uint64
+	queue   priorityQueue
+}
+
+// NewPriority returns an executer that will execute functions
+// on at most, n, goroutines simultaneously. Functions that are
+// waiting for a goroutine are executed in order of the priority
+// of their context (see WithPriority), and functions with the
+// same priority are executed in the order they were submitted.
+// If aging > 0, then the priority of a waiting function is
+// increased by one for each aging period that it has waited,
+// so that functions with a low priority are not starved.
+// If n <= 0 then the value provided by DefaultLimit will be used.
+// Functions with a context that is done when a goroutine becomes
+// available are rejected with the context error.
+func NewPriority(n int, aging time.Duration) Executer {
+	return newPriority(n, aging, realClock{})
+}
+
+func newPriority(n int, aging time.Duration, c clock) *prioritized {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	return &prioritized{
+		clock: c,
+		start: c.Now(),
+		n:     n,
+		queue: priorityQueue{aging: aging},
+	}
+}
+
+func (p *prioritized) Execute(ctx context.Context, f func(context.Context)) {
+	if err := ctx.Err(); err != nil {
+		Reject(ctx, f, err)
+		return
+	}
+
+	t := priorityTask{
+		task:     task{ctx: ctx, f: f},
+		priority: Priority(ctx),
+	}
+
+	p.mutex.Lock()
+	if p.running < p.n {
+		p.running++
+		p.mutex.Unlock()
+		go p.run(t.task)
+		return
+	}
+
+	t.seq = p.seq
+	t.queued = p.clock.Now().Sub(p.start)
+	p.seq++
+	heap.Push(&p.queue, t)
+	p.mutex.Unlock()
+}
+
+// run executes the task and then any queued tasks
+// until there are none remaining in the queue.
+func (p *prioritized) run(t task) {
+	for {
+		if err := t.ctx.Err(); err != nil {
+			Reject(t.ctx, t.f, err)
+		} else {
+			t.f(t.ctx)
+		}
+
+		p.mutex.Lock()
+		if p.queue.Len() == 0 {
+			p.running--
+			p.mutex.Unlock()
+			return
+		}
+		t = heap.Pop(&p.queue).(priorityTask).task
+		p.mutex.Unlock()
+	}
+}
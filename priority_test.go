@@ -0,0 +1,158 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// priorityWork starts a work group, with the given priority, that
+// records the names of its workers, and waits for them to be queued.
+func priorityWork(p *prioritized, priority int, record func(string), names ...string) <-chan error {
+	workers := make([]Worker, len(names))
+	for i, n := range names {
+		name := n
+		workers[i] = func(ctx context.Context) error {
+			record(name)
+			return nil
+		}
+	}
+
+	p.mutex.Lock()
+	queued := p.queue.Len()
+	p.mutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Work(WithPriority(context.Background(), priority), p, CancelOnFirstError(), workers...)
+	}()
+
+	for {
+		p.mutex.Lock()
+		n := p.queue.Len()
+		p.mutex.Unlock()
+		if n == queued+len(names) {
+			return done
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+
+	var mutex sync.Mutex
+	var order []string
+
+	record := func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, name)
+	}
+
+	p := newPriority(1, 0, realClock{})
+
+	release := blockExecuter(p)
+
+	groups := []<-chan error{
+		priorityWork(p, -1, record, "low1", "low2"),
+		priorityWork(p, 0, record, "normal1", "normal2"),
+		priorityWork(p, 10, record, "high1", "high2"),
+		priorityWork(p, -1, record, "low3"),
+		priorityWork(p, 20, record, "urgent"),
+		priorityWork(p, 10, record, "high3"),
+	}
+
+	release()
+
+	for _, done := range groups {
+		if err := <-done; err != nil {
+			t.Fatalf("Work group error is not nil: %s", err)
+		}
+	}
+
+	expected := []string{"urgent", "high1", "high2", "high3", "normal1", "normal2", "low1", "low2", "low3"}
+	for i, name := range expected {
+		if order[i] != name {
+			t.Fatalf("Expecting order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+
+	var mutex sync.Mutex
+	var order []string
+
+	record := func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, name)
+	}
+
+	c := newFakeClock()
+	p := newPriority(1, time.Second, c)
+
+	release := blockExecuter(p)
+
+	groups := []<-chan error{
+		priorityWork(p, 0, record, "old"),
+	}
+	c.Advance(5 * time.Second)
+	groups = append(groups,
+		priorityWork(p, 10, record, "high"),
+		priorityWork(p, 4, record, "young"),
+		priorityWork(p, 5, record, "equal"),
+	)
+
+	release()
+
+	for _, done := range groups {
+		if err := <-done; err != nil {
+			t.Fatalf("Work group error is not nil: %s", err)
+		}
+	}
+
+	expected := []string{"high", "old", "equal", "young"}
+	for i, name := range expected {
+		if order[i] != name {
+			t.Fatalf("Expecting order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestPriorityCanceled(t *testing.T) {
+
+	p := newPriority(1, 0, realClock{})
+
+	release := blockExecuter(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WorkFor(ctx, p, CancelNeverFirstError(), 100,
+			func(ctx context.Context, index int) error {
+				t.Errorf("Worker %d must not start", index)
+				return nil
+			},
+		)
+	}()
+
+	for {
+		p.mutex.Lock()
+		n := p.queue.Len()
+		p.mutex.Unlock()
+		if n == 100 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	release()
+
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Work group error is not canceled: %v", err)
+	}
+}
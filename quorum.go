@@ -0,0 +1,85 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// QuorumError is the error returned by the CancelOnQuorum manager when
+// the quorum is not reached, and holds the errors of the workers.
+type QuorumError struct {
+	MultiError
+	Quorum    int
+	Succeeded int
+}
+
+func (e *QuorumError) Error() string {
+	msg := fmt.Sprintf("quorum of %d not reached with %d succeeded", e.Quorum, e.Succeeded)
+	if len(e.Errors) == 0 {
+		return msg
+	}
+	return msg + ":" + e.list()
+}
+
+type quorum struct {
+	mutex     sync.Mutex
+	k         int
+	ncomplete int
+	nsuccess  int
+	canceled  bool
+	errs      []*IndexedError
+}
+
+// CancelOnQuorum initializes a new manager that cancels the work group
+// context when k workers complete without error. If the size of the work
+// group is known (see GroupSize), then the work group context is also
+// canceled as soon as too many workers complete with an error for the
+// quorum to be reached. If the quorum is not reached, then a QuorumError
+// is returned. If k <= 0 then a quorum of 1 is used.
+func CancelOnQuorum(k int) Manager {
+	if k <= 0 {
+		k = 1
+	}
+	return &quorum{k: k}
+}
+
+func (m *quorum) Error() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.nsuccess >= m.k {
+		return nil
+	}
+
+	err := &QuorumError{Quorum: m.k, Succeeded: m.nsuccess}
+	if merr := newMultiError(m.errs); merr != nil {
+		err.MultiError = *merr
+	}
+	return err
+}
+
+func (m *quorum) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.ncomplete++
+	if *err == nil {
+		m.nsuccess++
+	} else if !m.canceled || !errors.Is(*err, context.Canceled) {
+		m.errs = append(m.errs, &IndexedError{Index: idx, Err: *err})
+	}
+
+	if !m.canceled {
+		if m.nsuccess >= m.k {
+			m.canceled = true
+			c.Cancel()
+		} else if size, ok := GroupSize(ctx); ok && len(m.errs) > size-m.k {
+			m.canceled = true
+			c.Cancel()
+		}
+	}
+
+	return m.ncomplete
+}
@@ -0,0 +1,123 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCancelOnQuorumReached(t *testing.T) {
+
+	var started int
+
+	err := WorkFor(context.Background(), NewLimited(1), CancelOnQuorum(2), 5,
+		func(ctx context.Context, index int) error {
+			started++
+			if index == 0 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if started != 3 {
+		t.Fatalf("Expecting 3 workers to start, got %d", started)
+	}
+}
+
+func TestCancelOnQuorumImpossible(t *testing.T) {
+
+	var started int
+
+	err := WorkFor(context.Background(), NewLimited(1), CancelOnQuorum(3), 5,
+		func(ctx context.Context, index int) error {
+			started++
+			return fmt.Errorf("worker %d failed", index)
+		},
+	)
+
+	var qerr *QuorumError
+	if !errors.As(err, &qerr) {
+		t.Fatalf("Work group error is not a quorum error: %v", err)
+	}
+
+	if qerr.Quorum != 3 || qerr.Succeeded != 0 || len(qerr.Errors) != 3 {
+		t.Fatalf("Expecting quorum error with 3 errors: %v", err)
+	}
+
+	expected := "quorum of 3 not reached with 0 succeeded:" +
+		"\n\t* worker 0: worker 0 failed" +
+		"\n\t* worker 1: worker 1 failed" +
+		"\n\t* worker 2: worker 2 failed"
+	if err.Error() != expected {
+		t.Fatalf("Expecting error %q, got %q", expected, err.Error())
+	}
+
+	var ierr *IndexedError
+	if !errors.As(err, &ierr) || ierr.Index != 0 {
+		t.Fatalf("Expecting work group error to be indexed error of worker 0: %v", ierr)
+	}
+
+	if started != 3 {
+		t.Fatalf("Expecting 3 workers to start, got %d", started)
+	}
+}
+
+func TestCancelOnQuorumUnknownSize(t *testing.T) {
+
+	workers := make(chan Worker, 3)
+	for i := 0; i < 3; i++ {
+		index := i
+		workers <- func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				t.Errorf("Worker %d must not see a canceled context", index)
+			}
+			if index < 2 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		}
+	}
+	close(workers)
+
+	err := WorkChan(context.Background(), NewLimited(1), CancelOnQuorum(2), workers)
+
+	var qerr *QuorumError
+	if !errors.As(err, &qerr) {
+		t.Fatalf("Work group error is not a quorum error: %v", err)
+	}
+
+	if qerr.Succeeded != 1 || len(qerr.Errors) != 2 {
+		t.Fatalf("Expecting quorum error with 1 succeeded and 2 errors: %v", err)
+	}
+}
+
+func TestGroupSize(t *testing.T) {
+
+	err := WorkFor(context.Background(), nil, CancelOnFirstError(), 7,
+		func(ctx context.Context, index int) error {
+			if n, ok := GroupSize(ctx); !ok || n != 7 {
+				return fmt.Errorf("worker %d group size is %d", index, n)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	wg := NewWorkGroup(context.Background(), nil, CancelOnFirstError())
+	wg.Go(func(ctx context.Context) error {
+		if n, ok := GroupSize(ctx); ok {
+			return fmt.Errorf("worker group size is %d", n)
+		}
+		return nil
+	})
+	if err := wg.Wait(); err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
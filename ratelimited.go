@@ -0,0 +1,99 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type rateLimited struct {
+	mutex  sync.Mutex
+	clock  clock
+	inner  Executer
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimited returns an executer that delays functions, using
+// a token bucket, so that they are started at the given rate per
+// second, with bursts of up to burst functions, and then passes them
+// to the inner executer for execution. If the context is done while
+// waiting, then the function is rejected with the context error.
+// The executer may be shared by many work groups, which then share
+// the rate. If rate <= 0 then functions are not delayed, and if
+// burst < 1 then a burst of one is used. If inner is not provided
+// then DefaultExecuter is called to obtain the default.
+func NewRateLimited(rate float64, burst int, inner Executer) Executer {
+	return newRateLimited(rate, burst, inner, realClock{})
+}
+
+func newRateLimited(rate float64, burst int, inner Executer, c clock) *rateLimited {
+	if burst < 1 {
+		burst = 1
+	}
+	if inner == nil {
+		inner = DefaultExecuter()
+	}
+	return &rateLimited{
+		clock:  c,
+		inner:  inner,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   c.Now(),
+	}
+}
+
+// reserve takes a token from the bucket and returns
+// the duration to wait before the token is available.
+func (r *rateLimited) reserve() time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
+
+// unreserve returns a token that is no longer needed to the bucket.
+func (r *rateLimited) unreserve() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tokens++
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+}
+
+func (r *rateLimited) Execute(ctx context.Context, f func(context.Context)) {
+	if err := ctx.Err(); err != nil {
+		Reject(ctx, f, err)
+		return
+	}
+
+	if r.rate > 0 {
+		if d := r.reserve(); d > 0 {
+			select {
+			case <-r.clock.After(d):
+			case <-ctx.Done():
+				r.unreserve()
+				Reject(ctx, f, ctx.Err())
+				return
+			}
+		}
+	}
+
+	r.inner.Execute(ctx, f)
+}
@@ -0,0 +1,149 @@
+package workgroup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordExecuter returns an executer that records the time
+// of the clock when each function is submitted to it.
+func recordExecuter(c clock) (Executer, func() []time.Duration) {
+	var mutex sync.Mutex
+	var times []time.Duration
+
+	start := c.Now()
+
+	e := ExecuterFunc(func(ctx context.Context, f func(context.Context)) {
+		mutex.Lock()
+		times = append(times, c.Now().Sub(start))
+		mutex.Unlock()
+		go f(ctx)
+	})
+
+	return e, func() []time.Duration {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		return times
+	}
+}
+
+// advanceUntil advances the clock to the next timer each time
+// all the work groups are waiting for a timer, until all the
+// work groups are done, and then returns their errors.
+func advanceUntil(c *fakeClock, done ...<-chan error) []error {
+	errs := make([]error, len(done))
+	remaining := len(done)
+	for remaining > 0 {
+		for i := range done {
+			if done[i] == nil {
+				continue
+			}
+			select {
+			case errs[i] = <-done[i]:
+				done[i] = nil
+				remaining--
+			default:
+			}
+		}
+
+		if remaining > 0 && c.Timers() == remaining {
+			c.AdvanceNext()
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return errs
+}
+
+func TestRateLimited(t *testing.T) {
+
+	c := newFakeClock()
+	inner, times := recordExecuter(c)
+	e := newRateLimited(10, 2, inner, c)
+
+	work := func() <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			done <- WorkFor(context.Background(), e, CancelOnFirstError(), 5,
+				func(ctx context.Context, index int) error {
+					return nil
+				},
+			)
+		}()
+		return done
+	}
+
+	for _, err := range advanceUntil(c, work(), work()) {
+		if err != nil {
+			t.Fatalf("Work group error is not nil: %s", err)
+		}
+	}
+
+	expected := []time.Duration{0, 0}
+	for i := 1; i <= 8; i++ {
+		expected = append(expected, time.Duration(i)*100*time.Millisecond)
+	}
+
+	actual := times()
+	if len(actual) != len(expected) {
+		t.Fatalf("Expecting times %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("Expecting times %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestRateLimitedCanceled(t *testing.T) {
+
+	c := newFakeClock()
+	inner, times := recordExecuter(c)
+	e := newRateLimited(1, 1, inner, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WorkFor(ctx, e, CancelNeverFirstError(), 100,
+			func(ctx context.Context, index int) error {
+				return nil
+			},
+		)
+	}()
+
+	for c.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Work group error is not canceled: %v", err)
+	}
+
+	if len(times()) != 1 {
+		t.Fatalf("Expecting only 1 worker to start, got %d", len(times()))
+	}
+
+	// The token reserved by the canceled
+	// worker is returned to the bucket.
+	c.Advance(time.Second)
+
+	err := WorkFor(context.Background(), e, CancelNeverFirstError(), 1,
+		func(ctx context.Context, index int) error {
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if len(times()) != 2 {
+		t.Fatalf("Expecting 2 workers to start, got %d", len(times()))
+	}
+}
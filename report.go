@@ -0,0 +1,124 @@
+package workgroup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WorkerReport is the report of a single worker of a work group.
+type WorkerReport struct {
+	Index       int
+	Wait        time.Duration // time waiting to start after being submitted
+	Run         time.Duration // time running
+	Err         error
+	Started     bool // false if the worker was rejected by the executer
+	AfterCancel bool // true if the worker started after the group was canceled
+}
+
+// Report is an Observer that records the execution of a work group,
+// for example, to find the slowest workers. It is used by providing
+// it to the context of the work group with WithObserver, and must be
+// used for only one work group. The fields must not be accessed
+// until the work group has completed.
+type Report struct {
+	mutex     sync.Mutex
+	indexes   map[int]int    // positions of the workers by index
+	Workers   []WorkerReport // ordered by index
+	Elapsed   time.Duration
+	Err       error
+	Succeeded int
+	Failed    int
+	Skipped   int // workers that did not start
+}
+
+// worker returns the report of the worker with the given
+// index, adding a report if there is none for the index.
+func (r *Report) worker(idx int) *WorkerReport {
+	if r.indexes == nil {
+		r.indexes = map[int]int{}
+	}
+	i, ok := r.indexes[idx]
+	if !ok {
+		i = len(r.Workers)
+		r.indexes[idx] = i
+		r.Workers = append(r.Workers, WorkerReport{Index: idx})
+	}
+	return &r.Workers[i]
+}
+
+// OnSubmit implements the Observer interface.
+func (r *Report) OnSubmit(ctx context.Context, idx int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.worker(idx)
+}
+
+// OnStart implements the Observer interface.
+func (r *Report) OnStart(ctx context.Context, idx int, wait time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	w := r.worker(idx)
+	w.Wait = wait
+	w.Started = true
+	w.AfterCancel = ctx.Err() != nil
+}
+
+// OnFinish implements the Observer interface.
+func (r *Report) OnFinish(ctx context.Context, idx int, run time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	w := r.worker(idx)
+	w.Err = err
+	switch {
+	case !w.Started:
+		r.Skipped++
+	case err != nil:
+		w.Run = run
+		r.Failed++
+	default:
+		w.Run = run
+		r.Succeeded++
+	}
+}
+
+// OnCancel implements the Observer interface.
+func (r *Report) OnCancel(ctx context.Context, idx int, err error) {}
+
+// OnGroupDone implements the Observer interface.
+func (r *Report) OnGroupDone(ctx context.Context, elapsed time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Elapsed = elapsed
+	r.Err = err
+
+	// Workers may be submitted out of order by a WorkGroup.
+	sort.Slice(r.Workers, func(i, j int) bool {
+		return r.Workers[i].Index < r.Workers[j].Index
+	})
+	for i, w := range r.Workers {
+		r.indexes[w.Index] = i
+	}
+}
+
+// Slowest returns the reports of at most n workers that started,
+// ordered by decreasing run time. If n < 0 then all are returned.
+func (r *Report) Slowest(n int) []WorkerReport {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var workers []WorkerReport
+	for _, w := range r.Workers {
+		if w.Started {
+			workers = append(workers, w)
+		}
+	}
+	sort.SliceStable(workers, func(i, j int) bool {
+		return workers[i].Run > workers[j].Run
+	})
+	if n >= 0 && len(workers) > n {
+		workers = workers[:n]
+	}
+	return workers
+}
@@ -0,0 +1,132 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReportSequential(t *testing.T) {
+
+	r := &Report{}
+
+	err := WorkFor(WithObserver(context.Background(), r), NewSequential(), CancelOnFirstError(), 5,
+		func(ctx context.Context, index int) error {
+			switch index {
+			case 0:
+				time.Sleep(time.Millisecond)
+			case 1:
+				time.Sleep(20 * time.Millisecond)
+			case 2:
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return ctx.Err()
+		},
+	)
+	if err == nil || err.Error() != "worker 2 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	if r.Err != err {
+		t.Fatalf("Expecting report error to be work group error: %v", r.Err)
+	}
+
+	if len(r.Workers) != 5 {
+		t.Fatalf("Expecting 5 worker reports, got %d", len(r.Workers))
+	}
+
+	if r.Succeeded != 2 || r.Failed != 3 || r.Skipped != 0 {
+		t.Fatalf("Expecting 2 succeeded, 3 failed and 0 skipped, got %d, %d and %d", r.Succeeded, r.Failed, r.Skipped)
+	}
+
+	for i, w := range r.Workers {
+		if w.Index != i || !w.Started {
+			t.Errorf("Expecting worker %d to be started: %+v", i, w)
+		}
+		if w.AfterCancel != (i > 2) {
+			t.Errorf("Expecting worker %d after cancel to be %v", i, i > 2)
+		}
+	}
+
+	if r.Workers[1].Run < 20*time.Millisecond {
+		t.Errorf("Expecting worker 1 to run for at least 20ms, got %s", r.Workers[1].Run)
+	}
+
+	if r.Elapsed < r.Workers[1].Run {
+		t.Errorf("Expecting elapsed time to be at least %s, got %s", r.Workers[1].Run, r.Elapsed)
+	}
+
+	slowest := r.Slowest(1)
+	if len(slowest) != 1 || slowest[0].Index != 1 {
+		t.Fatalf("Expecting worker 1 to be the slowest: %+v", slowest)
+	}
+
+	if len(r.Slowest(-1)) != 5 {
+		t.Fatalf("Expecting all workers to be returned")
+	}
+}
+
+func TestReportSkipped(t *testing.T) {
+
+	r := &Report{}
+
+	err := WorkFor(WithObserver(context.Background(), r), NewLimited(1), CancelOnFirstError(), 5,
+		func(ctx context.Context, index int) error {
+			if index == 2 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	if err == nil || err.Error() != "worker 2 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	if r.Succeeded != 2 || r.Failed != 1 || r.Skipped != 2 {
+		t.Fatalf("Expecting 2 succeeded, 1 failed and 2 skipped, got %d, %d and %d", r.Succeeded, r.Failed, r.Skipped)
+	}
+
+	for _, w := range r.Workers[3:] {
+		if w.Started || w.Err != context.Canceled {
+			t.Errorf("Expecting worker %d to be skipped: %+v", w.Index, w)
+		}
+	}
+}
+
+func TestReportWorkChan(t *testing.T) {
+
+	r := &Report{}
+
+	workers := make(chan Worker, 2)
+	workers <- func(ctx context.Context) error {
+		return nil
+	}
+	workers <- func(ctx context.Context) error {
+		return fmt.Errorf("worker failed")
+	}
+	close(workers)
+
+	err := WorkChan(WithObserver(context.Background(), r), NewLimited(1), CancelNeverFirstError(), workers)
+	if err == nil || err.Error() != "worker failed" {
+		t.Fatalf("Work group error is not worker error: %v", err)
+	}
+
+	if len(r.Workers) != 2 {
+		t.Fatalf("Expecting 2 worker reports, got %+v", r.Workers)
+	}
+
+	for i, w := range r.Workers {
+		if w.Index != i+1 || !w.Started {
+			t.Errorf("Expecting worker %d to be started: %+v", i+1, w)
+		}
+	}
+
+	if r.Succeeded != 1 || r.Failed != 1 || r.Skipped != 0 {
+		t.Fatalf("Expecting 1 succeeded, 1 failed and 0 skipped, got %d, %d and %d", r.Succeeded, r.Failed, r.Skipped)
+	}
+
+	if len(r.Slowest(-1)) != 2 {
+		t.Fatalf("Expecting all workers to be returned")
+	}
+}
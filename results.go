@@ -0,0 +1,55 @@
+package workgroup
+
+import (
+	"context"
+)
+
+// ResultWorker is a function that performs work and produces a result
+type ResultWorker[T any] func(context.Context) (T, error)
+
+// IdxResultWorker is a function that performs work for a given index
+// and produces a result
+type IdxResultWorker[T any] func(context.Context, int) (T, error)
+
+// WorkResults arranges for a group of workers to be executed
+// and then waits for these workers to complete. The results
+// are returned in the same order as the workers were provided.
+// The result of a worker is stored even if the worker returns
+// an error, so if the work group is canceled early then the
+// results of any workers that were not completed will be
+// whatever value those workers returned (usually the zero value).
+// See documention for Work() for details.
+func WorkResults[T any](ctx context.Context, e Executer, m Manager, g ...ResultWorker[T]) ([]T, error) {
+	results := make([]T, len(g))
+
+	workers := make([]Worker, len(g))
+	for i, w := range g {
+		index := i
+		worker := w
+		workers[i] = func(ctx context.Context) (err error) {
+			results[index], err = worker(ctx)
+			return err
+		}
+	}
+
+	err := Work(ctx, e, m, workers...)
+
+	return results, err
+}
+
+// WorkForResults arranges for the worker, w, to be executed n times
+// and waits for these workers to complete before returning. The
+// results are returned in index order. See documentation for
+// WorkResults() for details.
+func WorkForResults[T any](ctx context.Context, e Executer, m Manager, n int, w IdxResultWorker[T]) ([]T, error) {
+	results := make([]T, n)
+
+	err := WorkFor(ctx, e, m, n,
+		func(ctx context.Context, index int) (err error) {
+			results[index], err = w(ctx, index)
+			return err
+		},
+	)
+
+	return results, err
+}
@@ -0,0 +1,125 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSimpleWorkResults(t *testing.T) {
+
+	workers := make([]ResultWorker[int], 10000)
+	for i := 0; i < len(workers); i++ {
+		index := i
+		workers[i] = func(ctx context.Context) (int, error) {
+			time.Sleep(time.Millisecond)
+			return index * 2, nil
+		}
+	}
+
+	results, err := WorkResults(nil, nil, nil, workers...)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if len(results) != len(workers) {
+		t.Fatalf("Expecting %d results, got %d", len(workers), len(results))
+	}
+	for i, r := range results {
+		if r != i*2 {
+			t.Errorf("Result %d is incorrect: %d", i, r)
+		}
+	}
+}
+
+func TestSimpleWorkForResults(t *testing.T) {
+
+	results, err := WorkForResults(nil, nil, nil, 10000,
+		func(ctx context.Context, index int) (string, error) {
+			time.Sleep(time.Millisecond)
+			return fmt.Sprint(index), nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if len(results) != 10000 {
+		t.Fatalf("Expecting %d results, got %d", 10000, len(results))
+	}
+	for i, r := range results {
+		if r != fmt.Sprint(i) {
+			t.Errorf("Result %d is incorrect: %s", i, r)
+		}
+	}
+}
+
+func TestCancelOnFirstErrorWorkForResults(t *testing.T) {
+
+	results, err := WorkForResults(context.Background(), NewLimited(1), CancelOnFirstError(), 100,
+		func(ctx context.Context, index int) (int, error) {
+			if index == 50 {
+				return -1, fmt.Errorf("worker %d failed", index)
+			}
+
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			default:
+				return index + 1, nil
+			}
+		},
+	)
+
+	if err == nil || err.Error() != "worker 50 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	if len(results) != 100 {
+		t.Fatalf("Expecting %d results, got %d", 100, len(results))
+	}
+	for i, r := range results {
+		switch {
+		case i < 50:
+			if r != i+1 {
+				t.Errorf("Result %d is incorrect: %d", i, r)
+			}
+		case i == 50:
+			if r != -1 {
+				t.Errorf("Result %d of failed worker is incorrect: %d", i, r)
+			}
+		default:
+			if r != 0 {
+				t.Errorf("Result %d of canceled worker is not zero: %d", i, r)
+			}
+		}
+	}
+}
+
+func TestCancelOnFirstSuccessWorkResults(t *testing.T) {
+
+	results, err := WorkResults(context.Background(), NewUnlimited(), CancelOnFirstSuccess(),
+		func(ctx context.Context) (string, error) {
+			return "", fmt.Errorf("worker 0 failed")
+		},
+		func(ctx context.Context) (string, error) {
+			return "success", nil
+		},
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "canceled", ctx.Err()
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	expected := []string{"", "success", "canceled"}
+	for i, r := range results {
+		if r != expected[i] {
+			t.Errorf("Result %d is incorrect: %q", i, r)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package workgroup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// semaphore is a weighted semaphore, where waiters acquire
+// in the order that they arrive, so that a waiter with a
+// large weight is not starved by waiters with small weights.
+type semaphore struct {
+	mutex   sync.Mutex
+	size    int64
+	cur     int64
+	waiters list.List
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func newSemaphore(size int64) *semaphore {
+	return &semaphore{size: size}
+}
+
+// acquire waits to acquire a weight of n from the semaphore, or
+// for the context to be done in which case the error is returned.
+func (s *semaphore) acquire(ctx context.Context, n int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	if s.cur+n <= s.size && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mutex.Unlock()
+		return nil
+	}
+
+	w := &semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+		select {
+		case <-w.ready:
+			// Acquired after the context was done
+			s.cur -= n
+		default:
+			s.waiters.Remove(elem)
+		}
+		s.notify()
+		s.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// release returns a weight of n to the semaphore.
+func (s *semaphore) release(n int64) {
+	s.mutex.Lock()
+	s.cur -= n
+	s.notify()
+	s.mutex.Unlock()
+}
+
+// current returns the weight that is currently acquired.
+func (s *semaphore) current() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cur
+}
+
+// resize changes the size of the semaphore, if the size is reduced,
+// then waiters are blocked until the current weight is below the size.
+func (s *semaphore) resize(size int64) {
+	s.mutex.Lock()
+	s.size = size
+	s.notify()
+	s.mutex.Unlock()
+}
+
+// notify wakes the waiters at the front of the queue that are able to
+// acquire the semaphore, and must be called with the mutex locked.
+func (s *semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(*semaphoreWaiter)
+		if s.cur+w.n > s.size {
+			return
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
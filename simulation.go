@@ -0,0 +1,155 @@
+package workgroup
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+)
+
+type simulationKey struct{}
+
+type simulationTask struct {
+	task
+	sim     *Simulation
+	id      int
+	started bool
+	resume  chan struct{}
+}
+
+// Simulation is an executer for testing that executes functions one at a
+// time, in an order that is chosen by a pseudo-random generator. Functions
+// may also call Yield to allow another function to execute, and the order
+// in which they continue is also chosen by the generator. The schedule is
+// determined by the seed, so that a failing schedule can be replayed exactly
+// by using the same seed, provided the functions are otherwise deterministic.
+//
+// Functions only execute while a work group is waiting for them to complete,
+// so workers must not wait for each other, except by calling Yield, and must
+// not wait for goroutines that are not part of the simulation.
+type Simulation struct {
+	driver  sync.Mutex
+	mutex   sync.Mutex
+	rand    *rand.Rand
+	seed    int64
+	ntasks  int
+	pending []*simulationTask
+	trace   []int
+	park    chan struct{}
+	avail   chan struct{}
+}
+
+// NewSimulation returns a simulation executer with the given seed.
+func NewSimulation(seed int64) *Simulation {
+	return &Simulation{
+		rand:  rand.New(rand.NewSource(seed)),
+		seed:  seed,
+		park:  make(chan struct{}),
+		avail: make(chan struct{}),
+	}
+}
+
+// Seed returns the seed of this simulation.
+func (s *Simulation) Seed() int64 {
+	return s.seed
+}
+
+// Trace returns the identifiers of the functions in the order that they
+// were executed or continued, where the identifier of each function is
+// the order in which it was submitted, starting from zero.
+func (s *Simulation) Trace() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]int(nil), s.trace...)
+}
+
+// Execute queues the function, f, to be executed by the simulation.
+func (s *Simulation) Execute(ctx context.Context, f func(context.Context)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = append(s.pending, &simulationTask{
+		task:   task{ctx: ctx, f: f},
+		sim:    s,
+		id:     s.ntasks,
+		resume: make(chan struct{}),
+	})
+	s.ntasks++
+
+	close(s.avail)
+	s.avail = make(chan struct{})
+}
+
+// Wait executes functions until the done channel is closed. If it is
+// called from a function of the simulation, then the function yields
+// until the done channel is closed. See the Waiter interface.
+func (s *Simulation) Wait(ctx context.Context, done <-chan struct{}) {
+	if t, ok := ctx.Value(simulationKey{}).(*simulationTask); ok && t.sim == s {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				Yield(ctx)
+			}
+		}
+	}
+
+	s.driver.Lock()
+	defer s.driver.Unlock()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		s.mutex.Lock()
+		if len(s.pending) == 0 {
+			avail := s.avail
+			s.mutex.Unlock()
+
+			select {
+			case <-done:
+			case <-avail:
+			}
+			continue
+		}
+
+		i := s.rand.Intn(len(s.pending))
+		t := s.pending[i]
+		s.pending = append(s.pending[:i], s.pending[i+1:]...)
+		s.trace = append(s.trace, t.id)
+		s.mutex.Unlock()
+
+		if !t.started {
+			t.started = true
+			go func() {
+				<-t.resume
+				t.f(context.WithValue(t.ctx, simulationKey{}, t))
+				s.park <- struct{}{}
+			}()
+		}
+
+		t.resume <- struct{}{}
+		<-s.park
+	}
+}
+
+// Yield allows another function of a simulation to execute, if the
+// context is that of a function executed by a Simulation, otherwise
+// it does nothing.
+func Yield(ctx context.Context) {
+	t, ok := ctx.Value(simulationKey{}).(*simulationTask)
+	if !ok {
+		return
+	}
+
+	s := t.sim
+	s.mutex.Lock()
+	s.pending = append(s.pending, t)
+	s.mutex.Unlock()
+
+	s.park <- struct{}{}
+	<-t.resume
+}
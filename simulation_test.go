@@ -0,0 +1,129 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// simulateWork executes a work group on a simulation, where the workers
+// yield before and after checking for cancellation, and odd workers fail.
+func simulateWork(seed int64, manager Manager) (*Simulation, *AccumulateManager, error) {
+	s := NewSimulation(seed)
+
+	m := &AccumulateManager{
+		manager: manager,
+	}
+
+	err := WorkFor(context.Background(), s, m, 8,
+		func(ctx context.Context, index int) error {
+			Yield(ctx)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			Yield(ctx)
+			if index%2 == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+	return s, m, err
+}
+
+func TestSimulationManagers(t *testing.T) {
+
+	managers := map[string]func() Manager{
+		"CancelOnFirstError":    CancelOnFirstError,
+		"CancelOnFirstSuccess":  CancelOnFirstSuccess,
+		"CancelOnFirstComplete": CancelOnFirstComplete,
+		"CancelNeverFirstError": CancelNeverFirstError,
+	}
+
+	for name, manager := range managers {
+		for seed := int64(0); seed < 200; seed++ {
+			_, m, err := simulateWork(seed, manager())
+
+			if len(m.Errors) != 8 {
+				t.Fatalf("%s (seed %d): Expecting 8 workers to be managed, got %d", name, seed, len(m.Errors))
+			}
+
+			var firstError error
+			success := false
+			for _, e := range m.Errors {
+				if e == nil {
+					success = true
+				} else if firstError == nil {
+					firstError = e
+				}
+			}
+
+			var expected error
+			switch name {
+			case "CancelOnFirstError", "CancelNeverFirstError":
+				expected = firstError
+			case "CancelOnFirstSuccess":
+				if !success {
+					expected = firstError
+				}
+			case "CancelOnFirstComplete":
+				expected = m.Errors[0]
+			}
+
+			if err != expected {
+				t.Fatalf("%s (seed %d): Expecting error %v, got %v (errors %v)", name, seed, expected, err, m.Errors)
+			}
+		}
+	}
+}
+
+func TestSimulationReplay(t *testing.T) {
+
+	for seed := int64(0); seed < 20; seed++ {
+		s1, m1, err1 := simulateWork(seed, CancelOnFirstSuccess())
+		s2, m2, err2 := simulateWork(seed, CancelOnFirstSuccess())
+
+		if fmt.Sprint(s1.Trace()) != fmt.Sprint(s2.Trace()) {
+			t.Fatalf("Seed %d: Expecting same schedule, got %v and %v", seed, s1.Trace(), s2.Trace())
+		}
+		if fmt.Sprint(m1.Errors) != fmt.Sprint(m2.Errors) || fmt.Sprint(err1) != fmt.Sprint(err2) {
+			t.Fatalf("Seed %d: Expecting same errors, got %v and %v", seed, m1.Errors, m2.Errors)
+		}
+	}
+
+	s1, _, _ := simulateWork(1, CancelNeverFirstError())
+	s2, _, _ := simulateWork(2, CancelNeverFirstError())
+	if fmt.Sprint(s1.Trace()) == fmt.Sprint(s2.Trace()) {
+		t.Fatalf("Expecting different schedules for different seeds: %v", s1.Trace())
+	}
+}
+
+func TestSimulationNested(t *testing.T) {
+
+	for seed := int64(0); seed < 50; seed++ {
+		s := NewSimulation(seed)
+
+		var order []string
+
+		record := func(name string) Worker {
+			return func(ctx context.Context) error {
+				Yield(ctx)
+				order = append(order, name)
+				return nil
+			}
+		}
+
+		err := Work(context.Background(), s, CancelOnFirstError(),
+			Group(s, CancelOnFirstError(), record("a1"), record("a2")),
+			Group(s, CancelOnFirstError(), record("b1"), record("b2")),
+			record("c1"),
+		)
+		if err != nil {
+			t.Fatalf("Seed %d: Work group error is not nil: %s", seed, err)
+		}
+
+		if len(order) != 5 {
+			t.Fatalf("Seed %d: Expecting 5 workers to complete, got %v", seed, order)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package workgroup
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+type stealingKey struct{}
+
+// stealingWorker is a goroutine of a stealing pool,
+// with its own queue of tasks that it has submitted.
+type stealingWorker struct {
+	pool    *StealingPool
+	deque   []task
+	base    *stealingSession
+	holder  *stealingSession
+	changed chan struct{}
+}
+
+// stealingSession identifies a goroutine that is executing tasks as a
+// worker of the pool, either the goroutine of the pool itself, or one
+// that is waiting for a nested work group. Only the goroutines executing
+// the tasks of the current holder of the worker may start a new session.
+type stealingSession struct {
+	w *stealingWorker
+}
+
+// StealingPool is an executer that executes functions on a fixed number
+// of goroutines, where each goroutine has its own queue for the functions
+// that it submits, and idle goroutines steal functions from the queues of
+// the others. A goroutine of the pool that is waiting for a nested work
+// group executes queued functions, rather than blocking, so that nested
+// work groups do not deadlock when all the goroutines are waiting.
+type StealingPool struct {
+	mutex    sync.Mutex
+	workers  []*stealingWorker
+	global   []task
+	next     int
+	sleeping int
+	closed   bool
+	avail    chan struct{}
+	quit     chan struct{}
+}
+
+// NewStealingPool initializes a new work stealing pool executer that
+// will execute functions on a fixed number of goroutines. If n <= 0
+// then the value in DefaultLimit is used. Note that the provided
+// context must be cancelled, or the pool closed, to ensure that the
+// pool releases all resources.
+func NewStealingPool(ctx context.Context, n int) *StealingPool {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	p := &StealingPool{
+		avail: make(chan struct{}),
+		quit:  make(chan struct{}),
+	}
+
+	if ctx != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				p.Close()
+			case <-p.quit:
+			}
+		}()
+	}
+
+	p.workers = make([]*stealingWorker, n)
+	for i := range p.workers {
+		w := &stealingWorker{pool: p, changed: make(chan struct{})}
+		w.base = &stealingSession{w: w}
+		w.holder = w.base
+		p.workers[i] = w
+	}
+	for _, w := range p.workers {
+		go p.work(w)
+	}
+	return p
+}
+
+// Execute queues the function, f, to be executed by the pool. If it
+// is called from a goroutine of the pool, then the function is added
+// to the queue of that goroutine, otherwise it is added to a global
+// queue. If the pool is closed, then the function is rejected with
+// ErrExecuterClosed, and functions with a context that is done when
+// they are taken from the queue are rejected with the context error.
+func (p *StealingPool) Execute(ctx context.Context, f func(context.Context)) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		Reject(ctx, f, ErrExecuterClosed)
+		return
+	}
+
+	t := task{ctx: ctx, f: f}
+	if ss := p.session(ctx); ss != nil {
+		ss.w.deque = append(ss.w.deque, t)
+	} else {
+		p.global = append(p.global, t)
+	}
+
+	if p.sleeping > 0 {
+		close(p.avail)
+		p.avail = make(chan struct{})
+	}
+	p.mutex.Unlock()
+}
+
+// Wait executes queued functions, if it is called from a goroutine of
+// the pool, until the done channel is closed. See the Waiter interface.
+// Since the goroutine is identified by its context, which may have been
+// inherited by other goroutines, only one goroutine at a time executes
+// functions as each goroutine of the pool, while the others wait.
+func (p *StealingPool) Wait(ctx context.Context, done <-chan struct{}) {
+	ss := p.session(ctx)
+	if ss == nil {
+		return
+	}
+
+	w := ss.w
+	var nested *stealingSession
+	for {
+		var changed <-chan struct{}
+		if nested, changed = p.hold(ss); nested != nil {
+			break
+		}
+		select {
+		case <-done:
+			return
+		case <-changed:
+		}
+	}
+
+	defer func() {
+		p.mutex.Lock()
+		w.holder = ss
+		close(w.changed)
+		w.changed = make(chan struct{})
+		p.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		t, avail, ok := p.take(w)
+		if ok {
+			p.run(nested, t)
+			continue
+		}
+
+		select {
+		case <-done:
+		case <-avail:
+		}
+		p.wake()
+	}
+}
+
+// Close closes the pool so that no further functions will be
+// executed. Functions that have been submitted, but not yet
+// started, are rejected with ErrExecuterClosed. Close does
+// not wait for functions that are executing to complete.
+func (p *StealingPool) Close() {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.quit)
+
+	queue := p.global
+	p.global = nil
+	for _, w := range p.workers {
+		queue = append(queue, w.deque...)
+		w.deque = nil
+	}
+	p.mutex.Unlock()
+
+	for _, t := range queue {
+		Reject(t.ctx, t.f, ErrExecuterClosed)
+	}
+}
+
+// session returns the session of a goroutine of this pool
+// that is identified by the context, or nil if there is none.
+func (p *StealingPool) session(ctx context.Context) *stealingSession {
+	if ss, ok := ctx.Value(stealingKey{}).(*stealingSession); ok && ss.w.pool == p {
+		return ss
+	}
+	return nil
+}
+
+// hold starts a nested session, if the session is the holder of its
+// worker, otherwise it returns a channel that is closed when the holder
+// of the worker changes.
+func (p *StealingPool) hold(ss *stealingSession) (*stealingSession, <-chan struct{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	w := ss.w
+	if w.holder != ss {
+		return nil, w.changed
+	}
+	w.holder = &stealingSession{w: w}
+	return w.holder, nil
+}
+
+func (p *StealingPool) work(w *stealingWorker) {
+	for {
+		t, avail, ok := p.take(w)
+		if ok {
+			p.run(w.base, t)
+			continue
+		}
+
+		select {
+		case <-avail:
+			p.wake()
+		case <-p.quit:
+			p.wake()
+			return
+		}
+	}
+}
+
+// take removes a task, first from the end of the queue of the worker,
+// then from the global queue, and then from the front of the queues
+// of the other workers. If there are no tasks, then it returns a
+// channel that is closed when a task is added.
+func (p *StealingPool) take(w *stealingWorker) (task, <-chan struct{}, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if n := len(w.deque); n > 0 {
+		t := w.deque[n-1]
+		w.deque[n-1] = task{}
+		w.deque = w.deque[:n-1]
+		return t, nil, true
+	}
+
+	if len(p.global) > 0 {
+		t := p.global[0]
+		p.global[0] = task{}
+		p.global = p.global[1:]
+		return t, nil, true
+	}
+
+	for i := range p.workers {
+		v := p.workers[(p.next+i)%len(p.workers)]
+		if len(v.deque) > 0 {
+			p.next = (p.next + i + 1) % len(p.workers)
+			t := v.deque[0]
+			v.deque[0] = task{}
+			v.deque = v.deque[1:]
+			return t, nil, true
+		}
+	}
+
+	p.sleeping++
+	return task{}, p.avail, false
+}
+
+// wake is called after waiting on the channel returned by take.
+func (p *StealingPool) wake() {
+	p.mutex.Lock()
+	p.sleeping--
+	p.mutex.Unlock()
+}
+
+func (p *StealingPool) run(ss *stealingSession, t task) {
+	if err := t.ctx.Err(); err != nil {
+		Reject(t.ctx, t.f, err)
+		return
+	}
+	t.f(context.WithValue(t.ctx, stealingKey{}, ss))
+}
@@ -0,0 +1,184 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStealingPoolWorkFor(t *testing.T) {
+
+	counts := make([]int, 1000)
+	tokens := make(chan struct{}, 8)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	WorkFor(ctx, NewStealingPool(ctx, 8), CancelNeverFirstError(), len(counts),
+		func(ctx context.Context, index int) error {
+			select {
+			case tokens <- struct{}{}:
+				break
+			default:
+				t.Errorf("Worker %d must wait to send token", index)
+			}
+
+			time.Sleep(100 * time.Microsecond)
+			counts[index]++
+
+			<-tokens
+			return nil
+		},
+	)
+
+	for _, c := range counts {
+		if c != 1 {
+			t.Errorf("Worker %d has not completed", c)
+		}
+	}
+}
+
+// TestStealingPoolNested is a regression test for nested work groups
+// that deadlock on a pool when all its goroutines are waiting for
+// nested work groups that can not be executed.
+func TestStealingPoolNested(t *testing.T) {
+
+	var count int64
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewStealingPool(ctx, 2)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WorkFor(ctx, p, CancelOnFirstError(), 8,
+			func(ctx context.Context, i int) error {
+				return WorkFor(ctx, p, CancelOnFirstError(), 8,
+					func(ctx context.Context, j int) error {
+						return Work(ctx, p, CancelOnFirstError(),
+							GroupFor(p, CancelOnFirstError(), 4,
+								func(ctx context.Context, k int) error {
+									atomic.AddInt64(&count, 1)
+									return nil
+								},
+							),
+							func(ctx context.Context) error {
+								atomic.AddInt64(&count, 1)
+								return nil
+							},
+						)
+					},
+				)
+			},
+		)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Work group error is not nil: %s", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Nested work groups are deadlocked")
+	}
+
+	if count != 8*8*5 {
+		t.Fatalf("Expecting %d workers to complete, got %d", 8*8*5, count)
+	}
+}
+
+// TestStealingPoolNestedUnlimited is a regression test for goroutines,
+// that are not of the pool, but inherit the context of a goroutine of
+// the pool, executing functions as if they were that goroutine.
+func TestStealingPoolNestedUnlimited(t *testing.T) {
+
+	for n := 1; n <= 2; n++ {
+		var running, peak int64
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p := NewStealingPool(ctx, n)
+
+		err := WorkFor(ctx, p, CancelOnFirstError(), n,
+			func(ctx context.Context, i int) error {
+				return WorkFor(ctx, NewUnlimited(), CancelOnFirstError(), 8,
+					func(ctx context.Context, j int) error {
+						return WorkFor(ctx, p, CancelOnFirstError(), 4,
+							func(ctx context.Context, k int) error {
+								r := atomic.AddInt64(&running, 1)
+								defer atomic.AddInt64(&running, -1)
+								for {
+									m := atomic.LoadInt64(&peak)
+									if r <= m || atomic.CompareAndSwapInt64(&peak, m, r) {
+										break
+									}
+								}
+								time.Sleep(time.Millisecond)
+								return nil
+							},
+						)
+					},
+				)
+			},
+		)
+
+		cancel()
+
+		if err != nil {
+			t.Fatalf("Work group error is not nil: %s", err)
+		}
+
+		if peak > int64(n) {
+			t.Fatalf("Expecting a maximum of %d workers running, got %d", n, peak)
+		}
+	}
+}
+
+func TestStealingPoolClose(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewStealingPool(ctx, 1)
+
+	release := blockExecuter(p)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WorkFor(context.Background(), p, CancelNeverFirstError(), 100,
+			func(ctx context.Context, index int) error {
+				t.Errorf("Worker %d must not start", index)
+				return nil
+			},
+		)
+	}()
+
+	for {
+		p.mutex.Lock()
+		n := len(p.global)
+		p.mutex.Unlock()
+		if n == 100 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+
+	if err := <-done; err != ErrExecuterClosed {
+		t.Fatalf("Work group error is not closed error: %v", err)
+	}
+
+	release()
+
+	err := WorkFor(context.Background(), p, CancelNeverFirstError(), 1,
+		func(ctx context.Context, index int) error {
+			t.Errorf("Worker %d must not start", index)
+			return nil
+		},
+	)
+	if err != ErrExecuterClosed {
+		t.Fatalf("Work group error is not closed error: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package workgroup
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+type traceKey struct{}
+
+// WithTraceTask returns a copy of the context with which work groups
+// create a runtime/trace task, with the given name, for the group and
+// a region for each worker, so that they can be seen with go tool trace.
+// The index and outcome of each worker is logged with the category
+// "workgroup". Nested work groups create tasks that are children
+// of the task of their parent work group.
+func WithTraceTask(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, traceKey{}, name)
+}
+
+// traceTaskOf returns the name of the trace task of the context, if any.
+func traceTaskOf(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(traceKey{}).(string)
+	return name, ok
+}
+
+// traceWorker starts a trace region for the worker with the given index,
+// and returns a function that logs the error and ends the region.
+func traceWorker(ctx context.Context, index int, err *error) func() {
+	r := trace.StartRegion(ctx, "worker")
+	return func() {
+		if *err != nil {
+			trace.Logf(ctx, "workgroup", "worker %d: %s", index, *err)
+		} else {
+			trace.Logf(ctx, "workgroup", "worker %d: ok", index)
+		}
+		r.End()
+	}
+}
+
+// traceGroup logs the error and ends the trace task of the work group.
+func traceGroup(ctx context.Context, t *trace.Task, err error) {
+	if err != nil {
+		trace.Logf(ctx, "workgroup", "group: %s", err)
+	} else {
+		trace.Log(ctx, "workgroup", "group: ok")
+	}
+	t.End()
+}
@@ -0,0 +1,44 @@
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/trace"
+	"testing"
+)
+
+func TestWithTraceTask(t *testing.T) {
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("Tracing is not available: %s", err)
+	}
+
+	ctx := WithTraceTask(context.Background(), "trace-test-outer")
+
+	err := WorkFor(ctx, NewLimited(2), CancelNeverFirstError(), 4,
+		func(ctx context.Context, index int) error {
+			return WorkFor(WithTraceTask(ctx, "trace-test-inner"), nil, CancelNeverFirstError(), 2,
+				func(ctx context.Context, nested int) error {
+					if index == 3 && nested == 1 {
+						return fmt.Errorf("trace-test-failure")
+					}
+					return nil
+				},
+			)
+		},
+	)
+
+	trace.Stop()
+
+	if err == nil || err.Error() != "trace-test-failure" {
+		t.Fatalf("Work group error is not worker error: %v", err)
+	}
+
+	for _, s := range []string{"trace-test-outer", "trace-test-inner", "workgroup", "worker 3: trace-test-failure"} {
+		if !bytes.Contains(buf.Bytes(), []byte(s)) {
+			t.Errorf("Expecting trace to contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracer starts spans, and may be implemented by an adapter
+// for a tracing library, such as OpenTelemetry.
+type Tracer interface {
+	// Start starts a span with the given name, that is a child
+	// of the span of the context, if any, and returns a copy of
+	// the context with the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+type tracerKey struct{}
+
+// WithTracer returns a copy of the context with tracer, t, which is
+// used by work groups to start a span, named "workgroup", for the group
+// and a child span, named "worker", for each worker, with the attribute
+// "workgroup.index". The errors of the group and workers, including
+// panics recovered by the manager, are recorded on the spans. Nested
+// work groups use the same tracer, so that their spans are children
+// of the span of the worker.
+func WithTracer(ctx context.Context, t Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// tracerOf returns the tracer of the context, or nil if there is none.
+func tracerOf(ctx context.Context) Tracer {
+	t, _ := ctx.Value(tracerKey{}).(Tracer)
+	return t
+}
+
+// startWorkerSpan starts a span for the worker with the given index,
+// and returns a function that records the error and ends the span.
+func startWorkerSpan(ctx context.Context, t Tracer, index int, err *error) (context.Context, func()) {
+	ctx, span := t.Start(ctx, "worker")
+	span.SetAttribute("workgroup.index", index)
+	return ctx, func() {
+		if *err != nil {
+			span.RecordError(*err)
+		}
+		span.End()
+	}
+}
+
+// endGroupSpan records the error and ends the span of the work group.
+func endGroupSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// MemoryTracer is a Tracer that keeps the spans in memory, for testing.
+type MemoryTracer struct {
+	mutex sync.Mutex
+	spans []*MemorySpan
+}
+
+// MemorySpan is a span started by a MemoryTracer. The
+// fields must not be accessed until the span has ended.
+type MemorySpan struct {
+	mutex      sync.Mutex
+	Name       string
+	Parent     *MemorySpan
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+}
+
+type memorySpanKey struct{}
+
+// NewMemoryTracer initializes a new in-memory tracer.
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+// Start implements the Tracer interface.
+func (t *MemoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(memorySpanKey{}).(*MemorySpan)
+	span := &MemorySpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: map[string]interface{}{},
+	}
+
+	t.mutex.Lock()
+	t.spans = append(t.spans, span)
+	t.mutex.Unlock()
+
+	return context.WithValue(ctx, memorySpanKey{}, span), span
+}
+
+// Spans returns the spans that have been started, in the order they started.
+func (t *MemoryTracer) Spans() []*MemorySpan {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]*MemorySpan(nil), t.spans...)
+}
+
+// SetAttribute implements the Span interface.
+func (s *MemorySpan) SetAttribute(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Attributes[key] = value
+}
+
+// RecordError implements the Span interface.
+func (s *MemorySpan) RecordError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+// End implements the Span interface.
+func (s *MemorySpan) End() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Ended = true
+}
@@ -0,0 +1,135 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTracerSpans(t *testing.T) {
+
+	tracer := NewMemoryTracer()
+
+	ctx := WithTracer(context.Background(), tracer)
+
+	err := WorkFor(ctx, NewLimited(1), CancelNeverFirstError(), 3,
+		func(ctx context.Context, index int) error {
+			switch index {
+			case 1:
+				return fmt.Errorf("worker %d failed", index)
+			case 2:
+				return WorkFor(ctx, nil, CancelNeverFirstError(), 2,
+					func(ctx context.Context, index int) error {
+						return nil
+					},
+				)
+			}
+			return nil
+		},
+	)
+	if err == nil || err.Error() != "worker 1 failed" {
+		t.Fatalf("Work group error is not first error: %v", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 7 {
+		t.Fatalf("Expecting 7 spans, got %d", len(spans))
+	}
+
+	group := spans[0]
+	if group.Name != "workgroup" || group.Parent != nil || len(group.Errors) != 1 || group.Errors[0] != err {
+		t.Fatalf("Expecting group span with error: %+v", group)
+	}
+
+	workers := map[int]*MemorySpan{}
+	for _, s := range spans {
+		if !s.Ended {
+			t.Errorf("Expecting span %s to be ended", s.Name)
+		}
+		if s.Name == "worker" && s.Parent == group {
+			workers[s.Attributes["workgroup.index"].(int)] = s
+		}
+	}
+
+	if len(workers) != 3 {
+		t.Fatalf("Expecting 3 worker spans of the group, got %d", len(workers))
+	}
+
+	if len(workers[0].Errors) != 0 || len(workers[1].Errors) != 1 || workers[1].Errors[0] != err {
+		t.Fatalf("Expecting only worker 1 span to have error")
+	}
+
+	var nested []*MemorySpan
+	for _, s := range spans {
+		if s.Parent != nil && s.Parent.Parent == workers[2] {
+			nested = append(nested, s)
+		}
+	}
+
+	if len(nested) != 2 || nested[0].Parent.Name != "workgroup" {
+		t.Fatalf("Expecting 2 worker spans of the nested group, got %d", len(nested))
+	}
+}
+
+func TestTracerPanic(t *testing.T) {
+
+	tracer := NewMemoryTracer()
+
+	ctx := WithTracer(context.Background(), tracer)
+
+	WorkFor(ctx, nil, Recover(CancelNeverFirstError()), 1,
+		func(ctx context.Context, index int) error {
+			panic("worker panic")
+		},
+	)
+
+	spans := tracer.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expecting 2 spans, got %d", len(spans))
+	}
+
+	var perr *PanicError
+	if len(spans[1].Errors) != 1 || !errors.As(spans[1].Errors[0], &perr) {
+		t.Fatalf("Expecting worker span to have panic error: %v", spans[1].Errors)
+	}
+}
+
+func TestTracerRepanic(t *testing.T) {
+
+	tracer := NewMemoryTracer()
+	o := &recordObserver{}
+
+	ctx := WithTraceTask(WithObserver(WithTracer(context.Background(), tracer), o), "test")
+
+	defer func() {
+		if v := recover(); v != "worker panic" {
+			t.Fatalf("Work group did not panic: %v", v)
+		}
+
+		spans := tracer.Spans()
+		for _, s := range spans {
+			if !s.Ended {
+				t.Errorf("Expecting span %s to be ended", s.Name)
+			}
+		}
+
+		var perr *PanicError
+		if len(spans[0].Errors) != 1 || !errors.As(spans[0].Errors[0], &perr) || perr.Value != "worker panic" {
+			t.Fatalf("Expecting group span to have panic error: %v", spans[0].Errors)
+		}
+
+		if o.events[len(o.events)-1] != "done panic: worker panic" {
+			t.Fatalf("Expecting group done to be the last event: %v", o.events)
+		}
+	}()
+
+	WorkFor(ctx, nil, Repanic(CancelNeverFirstError()), 2,
+		func(ctx context.Context, index int) error {
+			if index == 1 {
+				panic("worker panic")
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,84 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"runtime"
+)
+
+// ErrOverweight is given to the manager in place of the error from a
+// worker with a weight that is greater than the capacity of the executer.
+var ErrOverweight = errors.New("weight exceeds capacity")
+
+// ErrInvalidWeight is given to the manager in place of the
+// error from a worker with a weight that is not positive.
+var ErrInvalidWeight = errors.New("weight is not positive")
+
+type weightKey struct{}
+
+// WithWeight returns a copy of the context with weight, w, which is
+// used by the weighted executer. Work groups with workers of differing
+// weights can be nested within a group using contexts of each weight.
+func WithWeight(ctx context.Context, w int64) context.Context {
+	return context.WithValue(ctx, weightKey{}, w)
+}
+
+// Weight returns the weight of the context, or one
+// if a weight has not been set with WithWeight.
+func Weight(ctx context.Context) int64 {
+	if w, ok := ctx.Value(weightKey{}).(int64); ok {
+		return w
+	}
+	return 1
+}
+
+type weighted struct {
+	capacity int64
+	sem      *semaphore
+}
+
+// NewWeighted returns an executer that will execute functions on
+// goroutines while the total weight of the executing functions does
+// not exceed the capacity. The weight of each function is provided
+// by its context (see WithWeight). Functions wait for capacity in the
+// order that they are submitted, so functions with a large weight are
+// not starved. Functions with a weight that exceeds the capacity
+// are rejected with ErrOverweight, and functions with a weight that
+// is not positive are rejected with ErrInvalidWeight. If capacity
+// <= 0 then the value provided by DefaultLimit will be used. If the
+// context is done while waiting, then the function is rejected with
+// the context error.
+func NewWeighted(capacity int64) Executer {
+	if capacity <= 0 {
+		capacity = int64(DefaultLimit)
+	}
+	if capacity <= 0 {
+		capacity = int64(runtime.NumCPU())
+	}
+	return &weighted{
+		capacity: capacity,
+		sem:      newSemaphore(capacity),
+	}
+}
+
+func (w *weighted) Execute(ctx context.Context, f func(context.Context)) {
+	n := Weight(ctx)
+	if n <= 0 {
+		Reject(ctx, f, ErrInvalidWeight)
+		return
+	}
+	if n > w.capacity {
+		Reject(ctx, f, ErrOverweight)
+		return
+	}
+
+	if err := w.sem.acquire(ctx, n); err != nil {
+		Reject(ctx, f, err)
+		return
+	}
+
+	go func() {
+		defer w.sem.release(n)
+		f(ctx)
+	}()
+}
@@ -0,0 +1,142 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWeightedCapacity(t *testing.T) {
+
+	var total, peak int64
+
+	e := NewWeighted(10)
+
+	weighted := func(weight int64, n int) Worker {
+		return func(ctx context.Context) error {
+			return WorkFor(WithWeight(ctx, weight), e, CancelOnFirstError(), n,
+				func(ctx context.Context, index int) error {
+					w := atomic.AddInt64(&total, weight)
+					defer atomic.AddInt64(&total, -weight)
+					for {
+						p := atomic.LoadInt64(&peak)
+						if w <= p || atomic.CompareAndSwapInt64(&peak, p, w) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				},
+			)
+		}
+	}
+
+	err := Work(context.Background(), nil, CancelOnFirstError(),
+		weighted(1, 200), weighted(3, 100), weighted(7, 50), weighted(10, 10),
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if peak > 10 {
+		t.Fatalf("Expecting a maximum total weight of 10, got %d", peak)
+	}
+}
+
+func TestWeightedOverweight(t *testing.T) {
+
+	e := NewWeighted(10)
+
+	err := WorkFor(WithWeight(context.Background(), 11), e, CancelNeverFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			t.Errorf("Worker %d must not start", index)
+			return nil
+		},
+	)
+
+	if err != ErrOverweight {
+		t.Fatalf("Work group error is not overweight error: %v", err)
+	}
+}
+
+func TestWeightedInvalidWeight(t *testing.T) {
+
+	e := NewWeighted(10)
+
+	for _, weight := range []int64{0, -5} {
+		err := WorkFor(WithWeight(context.Background(), weight), e, CancelNeverFirstError(), 10,
+			func(ctx context.Context, index int) error {
+				t.Errorf("Worker %d must not start", index)
+				return nil
+			},
+		)
+
+		if err != ErrInvalidWeight {
+			t.Fatalf("Work group error is not invalid weight error: %v", err)
+		}
+	}
+}
+
+func TestWeightedNotStarved(t *testing.T) {
+
+	var completed int64
+
+	e := NewWeighted(4)
+
+	light := make(chan error, 1)
+	go func() {
+		light <- WorkFor(context.Background(), e, CancelOnFirstError(), 200,
+			func(ctx context.Context, index int) error {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt64(&completed, 1)
+				return nil
+			},
+		)
+	}()
+
+	for atomic.LoadInt64(&completed) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var before int64
+	err := Work(WithWeight(context.Background(), 4), e, CancelOnFirstError(),
+		func(ctx context.Context) error {
+			before = atomic.LoadInt64(&completed)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if err := <-light; err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if before >= 200 {
+		t.Fatalf("Expecting heavy worker to start before light workers complete")
+	}
+}
+
+func TestWeightedCanceled(t *testing.T) {
+
+	e := NewWeighted(1)
+
+	release := blockExecuter(e)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := WorkFor(ctx, e, CancelNeverFirstError(), 100,
+		func(ctx context.Context, index int) error {
+			t.Errorf("Worker %d must not start", index)
+			return nil
+		},
+	)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Work group error is not deadline exceeded: %v", err)
+	}
+}
@@ -2,7 +2,6 @@ package workgroup
 
 import (
 	"context"
-	"sync"
 )
 
 // Worker is a function that performs work
@@ -20,39 +19,17 @@ type IdxWorker func(context.Context, int) error
 // is called to obtain the default. If manager, m, is not provied
 // then DefaultManager is called be obtain the default manager.
 func Work(ctx context.Context, e Executer, m Manager, g ...Worker) error {
-	if ctx == nil {
-		ctx = context.TODO()
-	}
-
-	if e == nil {
-		e = DefaultExecuter()
-	}
-
-	if m == nil {
-		m = DefaultManager()
-	}
-
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	wg := &sync.WaitGroup{}
+	grp := newGroup(ctx, e, m)
+	grp.size = len(g)
 
-	wg.Add(len(g))
 	for i, w := range g {
-		index := i
 		worker := w
-		e.Execute(ctx, func(ctx context.Context) {
-			defer wg.Done()
-
-			var err error
-			defer m.Manage(ctx, CancellerFunc(cancel), index, &err)
-			err = worker(ctx)
+		grp.execute(i, func(ctx context.Context, _ int) error {
+			return worker(ctx)
 		})
 	}
 
-	wg.Wait()
-
-	return m.Error()
+	return grp.wait()
 }
 
 // Group returns a worker that immediately calls the
@@ -67,38 +44,14 @@ func Group(e Executer, m Manager, g ...Worker) Worker {
 // and waits for these workers to complete before returning.
 // See documention for Work() for details.
 func WorkFor(ctx context.Context, e Executer, m Manager, n int, w IdxWorker) error {
-	if ctx == nil {
-		ctx = context.TODO()
-	}
-
-	if e == nil {
-		e = DefaultExecuter()
-	}
-
-	if m == nil {
-		m = DefaultManager()
-	}
+	grp := newGroup(ctx, e, m)
+	grp.size = n
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	wg := &sync.WaitGroup{}
-
-	wg.Add(n)
 	for i := 0; i < n; i++ {
-		index := i
-		e.Execute(ctx, func(ctx context.Context) {
-			defer wg.Done()
-
-			var err error
-			defer m.Manage(ctx, CancellerFunc(cancel), index, &err)
-			err = w(ctx, index)
-		})
+		grp.execute(i, w)
 	}
 
-	wg.Wait()
-
-	return m.Error()
+	return grp.wait()
 }
 
 // GroupFor returns a worker that immediately calls the
@@ -113,41 +66,19 @@ func GroupFor(e Executer, m Manager, n int, w IdxWorker) Worker {
 // to be executed and waits for the channel to be closed and all
 // workers to complete. See documention for Work() for details.
 func WorkChan(ctx context.Context, e Executer, m Manager, g <-chan Worker) error {
-	if ctx == nil {
-		ctx = context.TODO()
-	}
-
-	if e == nil {
-		e = DefaultExecuter()
-	}
-
-	if m == nil {
-		m = DefaultManager()
-	}
-
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	wg := &sync.WaitGroup{}
+	grp := newGroup(ctx, e, m)
 
+	// The indexes of workers start from 1
 	i := 0
 	for w := range g {
-		wg.Add(1)
 		i++
-		index := i
 		worker := w
-		e.Execute(ctx, func(ctx context.Context) {
-			defer wg.Done()
-
-			var err error
-			defer m.Manage(ctx, CancellerFunc(cancel), index, &err)
-			err = worker(ctx)
+		grp.execute(i, func(ctx context.Context, _ int) error {
+			return worker(ctx)
 		})
 	}
 
-	wg.Wait()
-
-	return m.Error()
+	return grp.wait()
 }
 
 // GroupChan returns a worker that immediately calls
@@ -73,6 +73,36 @@ func TestSimpleWorkChan(t *testing.T) {
 	}
 }
 
+func TestWorkChanIndexes(t *testing.T) {
+
+	m := &indexManager{Manager: CancelNeverFirstError()}
+
+	workers := make(chan Worker, 3)
+	for i := 0; i < 3; i++ {
+		workers <- func(ctx context.Context) error {
+			return nil
+		}
+	}
+	close(workers)
+
+	WorkChan(nil, NewInline(), m, workers)
+
+	if fmt.Sprint(m.indexes) != "[1 2 3]" {
+		t.Fatalf("Expecting worker indexes [1 2 3], got %v", m.indexes)
+	}
+}
+
+// indexManager records the indexes given to the manager.
+type indexManager struct {
+	Manager
+	indexes []int
+}
+
+func (m *indexManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m.indexes = append(m.indexes, idx)
+	return m.Manager.Manage(ctx, c, idx, err)
+}
+
 func TestLimitedWorkFor(t *testing.T) {
 
 	counts := make([]int, 10000)